@@ -0,0 +1,94 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"maps"
+	"sort"
+	"sync"
+)
+
+// AccessReport is a snapshot of the variable reads recorded by an Env with
+// access tracking enabled
+type AccessReport struct {
+	// Reads is the number of times each key was read, including the keys
+	// which were not present at the time
+	Reads map[string]int
+	// Missing is the sorted list of keys read which are not present
+	Missing []string
+	// Unread is the list of keys present which were never read, in the
+	// order they were added to the Env
+	Unread []string
+}
+
+type cAccess struct {
+	reads map[string]int
+	m     *sync.Mutex
+}
+
+func newAccess() (access *cAccess) {
+	access = &cAccess{
+		reads: make(map[string]int),
+		m:     &sync.Mutex{},
+	}
+	return
+}
+
+func (a *cAccess) record(key string) {
+	if a == nil {
+		return
+	}
+	a.m.Lock()
+	defer a.m.Unlock()
+	a.reads[key] += 1
+}
+
+func (a *cAccess) snapshot() (reads map[string]int) {
+	a.m.Lock()
+	defer a.m.Unlock()
+	reads = maps.Clone(a.reads)
+	return
+}
+
+func (c *cEnv) TrackAccess(enabled bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if !enabled {
+		c.access = nil
+	} else if c.access == nil {
+		c.access = newAccess()
+	}
+}
+
+func (c *cEnv) AccessReport() (report AccessReport) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	if c.access == nil {
+		return
+	}
+	report.Reads = c.access.snapshot()
+	for key := range report.Reads {
+		if _, present := c.data[key]; !present {
+			report.Missing = append(report.Missing, key)
+		}
+	}
+	sort.Strings(report.Missing)
+	for _, key := range c.order {
+		if _, read := report.Reads[key]; !read {
+			report.Unread = append(report.Unread, key)
+		}
+	}
+	return
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAccess(t *testing.T) {
+	Convey("Env.TrackAccess disabled", t, func() {
+		env := New()
+		env.Set("key", "value")
+		_, _ = env.Get("key")
+		report := env.AccessReport()
+		So(report.Reads, ShouldBeNil)
+		So(report.Missing, ShouldBeNil)
+		So(report.Unread, ShouldBeNil)
+	})
+
+	Convey("Env.AccessReport", t, func() {
+		env := New()
+		env.Set("DATABASE_URL", "postgres://")
+		env.Set("DATABSE_URL", "typo")
+		env.Set("DEBUG", "true")
+		env.Set("WORKERS", "4")
+		env.Set("RATIO", "0.5")
+		env.TrackAccess(true)
+		_, _ = env.Get("DATABASE_URL")
+		So(env.String("DATABASE_URL", ""), ShouldEqual, "postgres://")
+		So(env.Bool("DEBUG", false), ShouldBeTrue)
+		So(env.Int("WORKERS", 1), ShouldEqual, 4)
+		So(env.Float("RATIO", 1.0), ShouldEqual, 0.5)
+		So(env.Int("NOPE", 1), ShouldEqual, 1)
+		report := env.AccessReport()
+		So(report.Reads, ShouldEqual, map[string]int{
+			"DATABASE_URL": 2,
+			"DEBUG":        1,
+			"WORKERS":      1,
+			"RATIO":        1,
+			"NOPE":         1,
+		})
+		So(report.Missing, ShouldEqual, []string{"NOPE"})
+		So(report.Unread, ShouldEqual, []string{"DATABSE_URL"})
+
+		env.TrackAccess(true) // already tracking, counts are kept
+		So(env.AccessReport().Reads["DATABASE_URL"], ShouldEqual, 2)
+
+		cloned := env.Clone()
+		So(cloned.AccessReport().Reads, ShouldEqual, map[string]int{})
+		_, _ = cloned.Get("DEBUG")
+		So(cloned.AccessReport().Reads["DEBUG"], ShouldEqual, 1)
+		So(env.AccessReport().Reads["DEBUG"], ShouldEqual, 1)
+
+		env.TrackAccess(false)
+		So(env.AccessReport().Reads, ShouldBeNil)
+		env.TrackAccess(true)
+		So(env.AccessReport().Reads, ShouldEqual, map[string]int{})
+	})
+}
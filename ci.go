@@ -0,0 +1,252 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"path"
+	"strings"
+)
+
+// CI provider names reported by Env.CI
+const (
+	CIGitHubActions = "github-actions"
+	CIGitLab        = "gitlab"
+	CIJenkins       = "jenkins"
+	CIBuildkite     = "buildkite"
+	CICircleCI      = "circleci"
+	CIDrone         = "drone"
+	CIWoodpecker    = "woodpecker"
+	CITravis        = "travis"
+	CIAzure         = "azure-pipelines"
+	CIBitbucket     = "bitbucket"
+	// CIGeneric is reported when CI is true but no known provider is found
+	CIGeneric = "generic"
+)
+
+// CI describes the continuous integration run found in an Env. Fields the
+// provider does not supply are left empty
+type CI struct {
+	// Detected is true when running under any CI
+	Detected bool
+	// Provider is one of the CI provider name constants
+	Provider string
+	// Branch is the branch being built, the source branch for pull requests
+	Branch string
+	// Commit is the commit SHA being built
+	Commit string
+	// PullRequest is the pull (or merge) request number
+	PullRequest string
+	// JobURL is a link to the job or build in the provider's web interface
+	JobURL string
+	// IsPR is true when the run is for a pull (or merge) request
+	IsPR bool
+}
+
+// ciDetectors are tried in order, the first returning true decides the
+// provider. Providers sharing variable names with another must come before
+// the more general one
+var ciDetectors = []func(c *cEnv, ci *CI) (detected bool){
+	(*cEnv).ciGitHubActions,
+	(*cEnv).ciGitLab,
+	(*cEnv).ciWoodpecker,
+	(*cEnv).ciJenkins,
+	(*cEnv).ciBuildkite,
+	(*cEnv).ciCircleCI,
+	(*cEnv).ciDrone,
+	(*cEnv).ciTravis,
+	(*cEnv).ciAzure,
+	(*cEnv).ciBitbucket,
+}
+
+func (c *cEnv) CI() (ci CI) {
+	for _, detect := range ciDetectors {
+		if detect(c, &ci) {
+			ci.Detected = true
+			break
+		}
+	}
+	if !ci.Detected && c.Bool("CI", false) {
+		ci.Detected, ci.Provider = true, CIGeneric
+	}
+	if ci.PullRequest == "false" {
+		// Travis and Buildkite use "false" for no pull request
+		ci.PullRequest = ""
+	}
+	if ci.PullRequest != "" {
+		ci.IsPR = true
+	}
+	return
+}
+
+func (c *cEnv) ciGitHubActions(ci *CI) (detected bool) {
+	if detected = c.String("GITHUB_ACTIONS", "") == "true"; !detected {
+		return
+	}
+	ci.Provider = CIGitHubActions
+	ci.Commit = c.String("GITHUB_SHA", "")
+	event := c.String("GITHUB_EVENT_NAME", "")
+	ci.IsPR = event == "pull_request" || event == "pull_request_target"
+	ref := c.String("GITHUB_REF", "")
+	if number, ok := strings.CutPrefix(ref, "refs/pull/"); ok {
+		ci.PullRequest, _, _ = strings.Cut(number, "/")
+	}
+	if ci.IsPR {
+		ci.Branch = c.String("GITHUB_HEAD_REF", "")
+	} else if branch, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+		ci.Branch = branch
+	}
+	if server, repo, run := c.String("GITHUB_SERVER_URL", ""), c.String("GITHUB_REPOSITORY", ""), c.String("GITHUB_RUN_ID", ""); server != "" && repo != "" && run != "" {
+		ci.JobURL = strings.TrimSuffix(server, "/") + "/" + repo + "/actions/runs/" + run
+	}
+	return
+}
+
+func (c *cEnv) ciGitLab(ci *CI) (detected bool) {
+	if detected = c.String("GITLAB_CI", "") != ""; !detected {
+		return
+	}
+	ci.Provider = CIGitLab
+	ci.Commit = c.String("CI_COMMIT_SHA", "")
+	ci.PullRequest = c.String("CI_MERGE_REQUEST_IID", "")
+	ci.Branch = c.anyOf("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME", "CI_COMMIT_BRANCH", "CI_COMMIT_REF_NAME")
+	ci.JobURL = c.String("CI_JOB_URL", "")
+	return
+}
+
+func (c *cEnv) ciWoodpecker(ci *CI) (detected bool) {
+	if detected = c.String("CI", "") == "woodpecker"; !detected {
+		return
+	}
+	ci.Provider = CIWoodpecker
+	ci.Commit = c.String("CI_COMMIT_SHA", "")
+	ci.PullRequest = c.String("CI_COMMIT_PULL_REQUEST", "")
+	ci.Branch = c.anyOf("CI_COMMIT_SOURCE_BRANCH", "CI_COMMIT_BRANCH")
+	ci.JobURL = c.String("CI_PIPELINE_URL", "")
+	return
+}
+
+func (c *cEnv) ciJenkins(ci *CI) (detected bool) {
+	if detected = c.String("JENKINS_URL", "") != ""; !detected {
+		return
+	}
+	ci.Provider = CIJenkins
+	ci.Commit = c.String("GIT_COMMIT", "")
+	ci.PullRequest = c.String("CHANGE_ID", "")
+	if ci.PullRequest != "" {
+		ci.Branch = c.String("CHANGE_BRANCH", "")
+	} else {
+		ci.Branch = c.anyOf("BRANCH_NAME", "GIT_LOCAL_BRANCH")
+		if ci.Branch == "" {
+			// GIT_BRANCH includes the remote name, as in "origin/main"
+			ci.Branch = c.String("GIT_BRANCH", "")
+			if _, branch, found := strings.Cut(ci.Branch, "/"); found {
+				ci.Branch = branch
+			}
+		}
+	}
+	ci.JobURL = c.String("BUILD_URL", "")
+	return
+}
+
+func (c *cEnv) ciBuildkite(ci *CI) (detected bool) {
+	if detected = c.String("BUILDKITE", "") == "true"; !detected {
+		return
+	}
+	ci.Provider = CIBuildkite
+	ci.Commit = c.String("BUILDKITE_COMMIT", "")
+	ci.Branch = c.String("BUILDKITE_BRANCH", "")
+	ci.PullRequest = c.String("BUILDKITE_PULL_REQUEST", "")
+	ci.JobURL = c.String("BUILDKITE_BUILD_URL", "")
+	if job := c.String("BUILDKITE_JOB_ID", ""); ci.JobURL != "" && job != "" {
+		ci.JobURL += "#" + job
+	}
+	return
+}
+
+func (c *cEnv) ciCircleCI(ci *CI) (detected bool) {
+	if detected = c.String("CIRCLECI", "") == "true"; !detected {
+		return
+	}
+	ci.Provider = CICircleCI
+	ci.Commit = c.String("CIRCLE_SHA1", "")
+	ci.Branch = c.String("CIRCLE_BRANCH", "")
+	if ci.PullRequest = c.String("CIRCLE_PR_NUMBER", ""); ci.PullRequest == "" {
+		// CIRCLE_PULL_REQUEST is the pull request URL, ending in the number
+		if link := c.String("CIRCLE_PULL_REQUEST", ""); link != "" {
+			ci.PullRequest = path.Base(link)
+		}
+	}
+	ci.JobURL = c.String("CIRCLE_BUILD_URL", "")
+	return
+}
+
+func (c *cEnv) ciDrone(ci *CI) (detected bool) {
+	if detected = c.String("DRONE", "") == "true"; !detected {
+		return
+	}
+	ci.Provider = CIDrone
+	ci.Commit = c.String("DRONE_COMMIT_SHA", "")
+	ci.PullRequest = c.String("DRONE_PULL_REQUEST", "")
+	if ci.PullRequest != "" {
+		// DRONE_BRANCH is the target branch of a pull request
+		ci.Branch = c.anyOf("DRONE_SOURCE_BRANCH", "DRONE_BRANCH")
+	} else {
+		ci.Branch = c.String("DRONE_BRANCH", "")
+	}
+	ci.JobURL = c.String("DRONE_BUILD_LINK", "")
+	return
+}
+
+func (c *cEnv) ciTravis(ci *CI) (detected bool) {
+	if detected = c.String("TRAVIS", "") == "true"; !detected {
+		return
+	}
+	ci.Provider = CITravis
+	ci.Commit = c.String("TRAVIS_COMMIT", "")
+	ci.PullRequest = c.String("TRAVIS_PULL_REQUEST", "")
+	ci.Branch = c.anyOf("TRAVIS_PULL_REQUEST_BRANCH", "TRAVIS_BRANCH")
+	ci.JobURL = c.anyOf("TRAVIS_JOB_WEB_URL", "TRAVIS_BUILD_WEB_URL")
+	return
+}
+
+func (c *cEnv) ciAzure(ci *CI) (detected bool) {
+	if detected = strings.EqualFold(c.String("TF_BUILD", ""), "true"); !detected {
+		return
+	}
+	ci.Provider = CIAzure
+	ci.Commit = c.String("BUILD_SOURCEVERSION", "")
+	ci.PullRequest = c.anyOf("SYSTEM_PULLREQUEST_PULLREQUESTNUMBER", "SYSTEM_PULLREQUEST_PULLREQUESTID")
+	branch := c.anyOf("SYSTEM_PULLREQUEST_SOURCEBRANCH", "BUILD_SOURCEBRANCH")
+	ci.Branch = strings.TrimPrefix(branch, "refs/heads/")
+	if collection, project, build := c.String("SYSTEM_COLLECTIONURI", ""), c.String("SYSTEM_TEAMPROJECT", ""), c.String("BUILD_BUILDID", ""); collection != "" && project != "" && build != "" {
+		ci.JobURL = strings.TrimSuffix(collection, "/") + "/" + project + "/_build/results?buildId=" + build
+	}
+	return
+}
+
+func (c *cEnv) ciBitbucket(ci *CI) (detected bool) {
+	build := c.String("BITBUCKET_BUILD_NUMBER", "")
+	if detected = build != ""; !detected {
+		return
+	}
+	ci.Provider = CIBitbucket
+	ci.Commit = c.String("BITBUCKET_COMMIT", "")
+	ci.Branch = c.String("BITBUCKET_BRANCH", "")
+	ci.PullRequest = c.String("BITBUCKET_PR_ID", "")
+	if origin := c.String("BITBUCKET_GIT_HTTP_ORIGIN", ""); origin != "" {
+		ci.JobURL = strings.TrimSuffix(origin, "/") + "/addon/pipelines/home#!/results/" + build
+	}
+	return
+}
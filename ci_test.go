@@ -0,0 +1,173 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCI(t *testing.T) {
+	Convey("Env.CI", t, func() {
+		for idx, test := range []struct {
+			environ []string
+			output  CI
+		}{
+			{
+				environ: nil,
+				output:  CI{},
+			},
+			{
+				environ: []string{"CI=false"},
+				output:  CI{},
+			},
+			{
+				environ: []string{"CI=1"},
+				output:  CI{Detected: true, Provider: CIGeneric},
+			},
+			{
+				environ: []string{
+					"CI=true", "GITHUB_ACTIONS=true", "GITHUB_SHA=abc123",
+					"GITHUB_EVENT_NAME=push", "GITHUB_REF=refs/heads/main",
+					"GITHUB_SERVER_URL=https://github.com", "GITHUB_REPOSITORY=org/repo", "GITHUB_RUN_ID=42",
+				},
+				output: CI{
+					Detected: true, Provider: CIGitHubActions, Branch: "main", Commit: "abc123",
+					JobURL: "https://github.com/org/repo/actions/runs/42",
+				},
+			},
+			{
+				environ: []string{
+					"CI=true", "GITHUB_ACTIONS=true", "GITHUB_SHA=abc123",
+					"GITHUB_EVENT_NAME=pull_request", "GITHUB_REF=refs/pull/7/merge", "GITHUB_HEAD_REF=feature",
+				},
+				output: CI{
+					Detected: true, Provider: CIGitHubActions, Branch: "feature", Commit: "abc123",
+					PullRequest: "7", IsPR: true,
+				},
+			},
+			{
+				environ: []string{
+					"CI=true", "GITLAB_CI=true", "CI_COMMIT_SHA=abc123", "CI_COMMIT_REF_NAME=main",
+					"CI_MERGE_REQUEST_IID=12", "CI_MERGE_REQUEST_SOURCE_BRANCH_NAME=feature",
+					"CI_JOB_URL=https://gitlab.com/org/repo/-/jobs/1",
+				},
+				output: CI{
+					Detected: true, Provider: CIGitLab, Branch: "feature", Commit: "abc123",
+					PullRequest: "12", JobURL: "https://gitlab.com/org/repo/-/jobs/1", IsPR: true,
+				},
+			},
+			{
+				environ: []string{
+					"CI=woodpecker", "CI_COMMIT_SHA=abc123", "CI_COMMIT_BRANCH=main",
+					"CI_PIPELINE_URL=https://ci.example.com/repos/1/pipeline/3",
+				},
+				output: CI{
+					Detected: true, Provider: CIWoodpecker, Branch: "main", Commit: "abc123",
+					JobURL: "https://ci.example.com/repos/1/pipeline/3",
+				},
+			},
+			{
+				environ: []string{
+					"JENKINS_URL=https://jenkins.example.com/", "GIT_COMMIT=abc123",
+					"GIT_BRANCH=origin/release/1.0", "BUILD_URL=https://jenkins.example.com/job/x/1/",
+				},
+				output: CI{
+					Detected: true, Provider: CIJenkins, Branch: "release/1.0", Commit: "abc123",
+					JobURL: "https://jenkins.example.com/job/x/1/",
+				},
+			},
+			{
+				environ: []string{
+					"JENKINS_URL=https://jenkins.example.com/", "GIT_COMMIT=abc123",
+					"BRANCH_NAME=PR-5", "CHANGE_ID=5", "CHANGE_BRANCH=feature",
+				},
+				output: CI{
+					Detected: true, Provider: CIJenkins, Branch: "feature", Commit: "abc123",
+					PullRequest: "5", IsPR: true,
+				},
+			},
+			{
+				environ: []string{
+					"CI=true", "BUILDKITE=true", "BUILDKITE_COMMIT=abc123", "BUILDKITE_BRANCH=main",
+					"BUILDKITE_PULL_REQUEST=false", "BUILDKITE_BUILD_URL=https://buildkite.com/org/p/builds/9",
+					"BUILDKITE_JOB_ID=j1",
+				},
+				output: CI{
+					Detected: true, Provider: CIBuildkite, Branch: "main", Commit: "abc123",
+					JobURL: "https://buildkite.com/org/p/builds/9#j1",
+				},
+			},
+			{
+				environ: []string{
+					"CI=true", "CIRCLECI=true", "CIRCLE_SHA1=abc123", "CIRCLE_BRANCH=feature",
+					"CIRCLE_PULL_REQUEST=https://github.com/org/repo/pull/33",
+					"CIRCLE_BUILD_URL=https://circleci.com/gh/org/repo/8",
+				},
+				output: CI{
+					Detected: true, Provider: CICircleCI, Branch: "feature", Commit: "abc123",
+					PullRequest: "33", JobURL: "https://circleci.com/gh/org/repo/8", IsPR: true,
+				},
+			},
+			{
+				environ: []string{
+					"CI=true", "DRONE=true", "DRONE_COMMIT_SHA=abc123", "DRONE_BRANCH=main",
+					"DRONE_SOURCE_BRANCH=feature", "DRONE_PULL_REQUEST=4",
+					"DRONE_BUILD_LINK=https://drone.example.com/org/repo/2",
+				},
+				output: CI{
+					Detected: true, Provider: CIDrone, Branch: "feature", Commit: "abc123",
+					PullRequest: "4", JobURL: "https://drone.example.com/org/repo/2", IsPR: true,
+				},
+			},
+			{
+				environ: []string{
+					"CI=true", "TRAVIS=true", "TRAVIS_COMMIT=abc123", "TRAVIS_BRANCH=main",
+					"TRAVIS_PULL_REQUEST=false", "TRAVIS_JOB_WEB_URL=https://travis-ci.com/org/repo/jobs/1",
+				},
+				output: CI{
+					Detected: true, Provider: CITravis, Branch: "main", Commit: "abc123",
+					JobURL: "https://travis-ci.com/org/repo/jobs/1",
+				},
+			},
+			{
+				environ: []string{
+					"TF_BUILD=True", "BUILD_SOURCEVERSION=abc123", "BUILD_SOURCEBRANCH=refs/heads/main",
+					"SYSTEM_COLLECTIONURI=https://dev.azure.com/org/", "SYSTEM_TEAMPROJECT=proj", "BUILD_BUILDID=77",
+				},
+				output: CI{
+					Detected: true, Provider: CIAzure, Branch: "main", Commit: "abc123",
+					JobURL: "https://dev.azure.com/org/proj/_build/results?buildId=77",
+				},
+			},
+			{
+				environ: []string{
+					"CI=true", "BITBUCKET_BUILD_NUMBER=15", "BITBUCKET_COMMIT=abc123", "BITBUCKET_BRANCH=feature",
+					"BITBUCKET_PR_ID=3", "BITBUCKET_GIT_HTTP_ORIGIN=http://bitbucket.org/org/repo",
+				},
+				output: CI{
+					Detected: true, Provider: CIBitbucket, Branch: "feature", Commit: "abc123",
+					PullRequest: "3", JobURL: "http://bitbucket.org/org/repo/addon/pipelines/home#!/results/15", IsPR: true,
+				},
+			},
+		} {
+			Convey(fmt.Sprintf("case %d: %q", idx, test.environ), func() {
+				So(NewImport(test.environ).CI(), ShouldEqual, test.output)
+			})
+		}
+	})
+}
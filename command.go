@@ -0,0 +1,84 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+var ErrEmptyCommand = errors.New("empty command")
+
+func (c *cEnv) With(overrides ...string) (env Env) {
+	env = c.Clone()
+	for _, override := range overrides {
+		if key, value, found := strings.Cut(override, "="); found {
+			env.Set(key, value)
+		}
+	}
+	return
+}
+
+func (c *cEnv) Only(keys ...string) (env Env) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	only := newEnv()
+	for _, key := range keys {
+		if value, present := c.data[key]; present {
+			only.Set(key, value)
+		}
+	}
+	env = only
+	return
+}
+
+func (c *cEnv) Command(ctx context.Context, name string, args ...string) (cmd *exec.Cmd) {
+	path, err := c.LookPath(name)
+	if ctx == nil {
+		cmd = exec.Command(name, args...)
+	} else {
+		cmd = exec.CommandContext(ctx, name, args...)
+	}
+	// replace the results of the process PATH search done by exec
+	if cmd.Path, cmd.Err = path, err; err != nil {
+		cmd.Path = name
+	}
+	cmd.Env = c.environ()
+	return
+}
+
+func (c *cEnv) Exec(argv []string) (err error) {
+	if len(argv) == 0 || argv[0] == "" {
+		err = ErrEmptyCommand
+		return
+	}
+	var path string
+	if path, err = c.LookPath(argv[0]); err != nil {
+		return
+	}
+	err = execve(path, argv, c.environ())
+	return
+}
+
+// environ is Environ without the nil result for an empty Env, which
+// exec.Cmd would take to mean the process environment
+func (c *cEnv) environ() (variables []string) {
+	if variables = c.Environ(); variables == nil {
+		variables = []string{}
+	}
+	return
+}
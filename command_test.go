@@ -0,0 +1,128 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCommandHelperProcess(t *testing.T) {
+	if os.Getenv("CORELIBS_ENV_TEST_EXEC") != "1" {
+		t.Skip("helper process for TestCommand")
+	}
+	env := NewImport([]string{"PATH=" + os.Getenv("PATH"), "GREETING=hello"})
+	err := env.Exec([]string{"sh", "-c", `echo "$GREETING"; echo "${CORELIBS_ENV_TEST_EXEC:-unset}"`})
+	t.Fatalf("exec returned: %v", err)
+}
+
+func TestCommand(t *testing.T) {
+	Convey("Env.With", t, func() {
+		env := New()
+		env.Set("key", "value")
+		with := env.With("key=other", "new='quoted'", "ignored")
+		So(with.Environ(), ShouldEqual, []string{"key=other", "new='quoted'"})
+		So(env.Environ(), ShouldEqual, []string{"key=value"})
+	})
+
+	Convey("Env.Only", t, func() {
+		env := New()
+		env.Set("one", "1")
+		env.Set("two", "2")
+		env.Set("three", "3")
+		So(env.Only("three", "one", "nope").Environ(), ShouldEqual, []string{"three=3", "one=1"})
+		So(env.Only().Len(), ShouldEqual, 0)
+	})
+
+	Convey("Env.Command", t, func() {
+		tempDir, err := os.MkdirTemp("", "corelibs-env.*.d")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(tempDir)
+		So(os.WriteFile(tempDir+"/greet", []byte("#!/bin/sh\necho \"$GREETING $1\"\n"), 0755), ShouldBeNil)
+		So(os.WriteFile(tempDir+"/plain", []byte("#!/bin/sh\n"), 0644), ShouldBeNil)
+
+		env := New()
+		env.Set("PATH", "/nope:"+tempDir+":"+os.Getenv("PATH"))
+		env.Set("GREETING", "hello")
+
+		cmd := env.Command(context.Background(), "greet", "world")
+		So(cmd.Err, ShouldBeNil)
+		So(cmd.Path, ShouldEqual, tempDir+"/greet")
+		So(cmd.Args, ShouldEqual, []string{"greet", "world"})
+		output, err := cmd.Output()
+		So(err, ShouldBeNil)
+		So(string(output), ShouldEqual, "hello world\n")
+
+		output, err = env.With("GREETING=goodbye").Command(nil, "greet", "world").Output()
+		So(err, ShouldBeNil)
+		So(string(output), ShouldEqual, "goodbye world\n")
+
+		output, err = env.Only("PATH").Command(nil, "greet").Output()
+		So(err, ShouldBeNil)
+		So(string(output), ShouldEqual, " \n")
+
+		output, err = New().Command(nil, "/bin/sh", "-c", "echo ${HOME:-unset}").Output()
+		So(err, ShouldBeNil)
+		So(string(output), ShouldEqual, "unset\n")
+
+		cmd = env.Command(nil, "plain")
+		So(errors.Is(cmd.Err, exec.ErrNotFound), ShouldBeTrue)
+		So(cmd.Run(), ShouldNotBeNil)
+		cmd = env.Command(nil, tempDir+"/plain")
+		So(errors.Is(cmd.Err, exec.ErrNotFound), ShouldBeTrue)
+
+		// relative PATH entries must not fall back to the process PATH
+		So(os.WriteFile(tempDir+"/ls", []byte("#!/bin/sh\necho local ls\n"), 0755), ShouldBeNil)
+		So(os.Mkdir(tempDir+"/bin", 0755), ShouldBeNil)
+		So(os.WriteFile(tempDir+"/bin/cat", []byte("#!/bin/sh\necho local cat\n"), 0755), ShouldBeNil)
+		pwd, _ := os.Getwd()
+		So(os.Chdir(tempDir), ShouldBeNil)
+		defer func() { _ = os.Chdir(pwd) }()
+		relative := New()
+		relative.Set("PATH", ".:bin")
+		cmd = relative.Command(nil, "ls")
+		So(cmd.Err, ShouldBeNil)
+		So(cmd.Path, ShouldEqual, "./ls")
+		output, err = cmd.Output()
+		So(err, ShouldBeNil)
+		So(string(output), ShouldEqual, "local ls\n")
+		cmd = relative.Command(context.Background(), "cat")
+		So(cmd.Path, ShouldEqual, "bin/cat")
+		output, err = cmd.Output()
+		So(err, ShouldBeNil)
+		So(string(output), ShouldEqual, "local cat\n")
+		cmd = relative.Command(nil, "sh")
+		So(errors.Is(cmd.Err, exec.ErrNotFound), ShouldBeTrue)
+		So(cmd.Path, ShouldEqual, "sh")
+		So(cmd.Run(), ShouldNotBeNil)
+	})
+
+	Convey("Env.Exec", t, func() {
+		env := New()
+		So(env.Exec(nil), ShouldEqual, ErrEmptyCommand)
+		So(errors.Is(env.Exec([]string{"not-a-command"}), exec.ErrNotFound), ShouldBeTrue)
+
+		cmd := exec.Command(os.Args[0], "-test.run=^TestCommandHelperProcess$")
+		cmd.Env = append(os.Environ(), "CORELIBS_ENV_TEST_EXEC=1")
+		output, err := cmd.Output()
+		So(err, ShouldBeNil)
+		So(string(output), ShouldEqual, "hello\nunset\n")
+	})
+}
@@ -0,0 +1,384 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var ErrRequiredVariable = errors.New("required variable is missing a value")
+
+// composeEscapes are the escape sequences expanded within double quotes
+var composeEscapes = regexp.MustCompile(`\\(?:[abcfnrtv$"\\]|0\d{0,3})`)
+
+// ParseComposeEnvFile reads the Docker Compose `.env` format, following the
+// compose-go dotenv parser, and returns the variables defined, using this
+// Env as the host environment:
+//
+//   - a leading UTF-8 byte order mark is removed and "\r\n" is read as "\n"
+//   - lines starting with "#" are comments and an "export " prefix is
+//     ignored
+//   - keys are made of letters, numbers and any of "_.-[]", followed by "="
+//     or ":", and a KEY alone on a line passes the host value through, or
+//     is skipped when the host does not have the KEY, or when the KEY is on
+//     the last line without a newline
+//   - unquoted values end at the line end or a " #" comment and have
+//     trailing whitespace removed
+//   - single quoted values are used as-is, except that "\'" is a quote
+//   - double quoted values may span lines and have "\\n" style escapes
+//     expanded, with "\$" giving a literal dollar sign
+//   - unquoted and double quoted values are interpolated, see
+//     InterpolateCompose, looking up the host variables before any defined
+//     earlier in the file
+//
+// See WithEncryptionKey for decrypting the values read from the file, which
+// happens before they are available for interpolation
+func (c *cEnv) ParseComposeEnvFile(r io.Reader, options ...FileOption) (parsed Env, err error) {
+	o := newFileOptions(options)
+	var data []byte
+	if data, err = io.ReadAll(r); err != nil {
+		return
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+	src := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	found := newEnv()
+	lookup := func(key string) (value string, present bool) {
+		if value, present = c.Get(key); !present {
+			value, present = found.Get(key)
+		}
+		return
+	}
+	p := &composeParser{line: 1, lookup: lookup}
+
+	for {
+		if src = p.statementStart(src); src == "" {
+			break
+		}
+		var key string
+		var inherited bool
+		if key, src, inherited, err = p.keyName(src); err != nil {
+			return
+		}
+		if strings.Contains(key, " ") {
+			err = fmt.Errorf("%w: line %d: key cannot contain a space", ErrEnvFileSyntax, p.line)
+			return
+		}
+		if inherited {
+			if value, present := c.Get(key); present {
+				found.Set(key, value)
+			}
+			continue
+		}
+		var value string
+		if value, src, err = p.value(src); err != nil {
+			return
+		} else if value, err = o.load(found, key, value); err != nil {
+			return
+		}
+		found.Set(key, value)
+	}
+
+	parsed = found
+	return
+}
+
+type composeParser struct {
+	line   int
+	lookup func(key string) (value string, present bool)
+}
+
+// statementStart skips whitespace and comment lines
+func (p *composeParser) statementStart(src string) (rest string) {
+	idx := strings.IndexFunc(src, func(r rune) bool {
+		if r == '\n' {
+			p.line += 1
+		}
+		return !unicode.IsSpace(r)
+	})
+	if idx < 0 {
+		return
+	}
+	if rest = src[idx:]; rest[0] != '#' {
+		return
+	}
+	if idx = strings.IndexByte(rest, '\n'); idx < 0 {
+		rest = ""
+		return
+	}
+	rest = p.statementStart(rest[idx:])
+	return
+}
+
+// keyName returns the key at the start of the `src` and the rest following
+// the separator, `inherited` is true when the key is alone on the line
+func (p *composeParser) keyName(src string) (key, rest string, inherited bool, err error) {
+	if after, found := strings.CutPrefix(src, "export"); found && after != "" && unicode.IsSpace(rune(after[0])) {
+		src = strings.TrimLeftFunc(after, composeSpace)
+	}
+
+	offset := 0
+loop:
+	for idx, r := range src {
+		if composeSpace(r) {
+			continue
+		}
+		switch r {
+		case '=', ':', '\n':
+			key, offset, inherited = src[:idx], idx+1, r == '\n'
+			break loop
+		case '_', '.', '-', '[', ']':
+		default:
+			if unicode.IsLetter(r) || unicode.IsNumber(r) {
+				continue
+			}
+			first, _, _ := strings.Cut(src, "\n")
+			err = fmt.Errorf("%w: line %d: unexpected character %q in variable name %q", ErrEnvFileSyntax, p.line, string(r), first)
+			return
+		}
+	}
+	// without a separator the key is empty and the whole line is read as
+	// a value, which is then ignored, as compose-go does for a KEY alone
+	// on the last line without a newline
+
+	if inherited && !strings.Contains(key, " ") {
+		p.line += 1
+	}
+	key = strings.TrimRightFunc(key, unicode.IsSpace)
+	rest = strings.TrimLeftFunc(src[offset:], composeSpace)
+	return
+}
+
+// value returns the value at the start of the `src` and the rest following
+func (p *composeParser) value(src string) (value, rest string, err error) {
+	if src == "" || (src[0] != '\'' && src[0] != '"') {
+		value, rest, _ = strings.Cut(src, "\n")
+		p.line += 1
+		value, _, _ = strings.Cut(value, " #")
+		value = strings.TrimRightFunc(value, unicode.IsSpace)
+		value, err = InterpolateCompose(value, p.lookup)
+		return
+	}
+
+	quote := src[0]
+	var escaped bool
+	var chars []byte
+	for idx := 1; idx < len(src); idx++ {
+		char := src[idx]
+		if char == '\n' {
+			p.line += 1
+		}
+		if char != quote {
+			if !escaped && char == '\\' {
+				escaped = true
+				continue
+			}
+			if escaped {
+				escaped = false
+				chars = append(chars, '\\')
+			}
+			chars = append(chars, char)
+			continue
+		}
+		if escaped {
+			// an escaped quote of the same kind
+			escaped = false
+			chars = append(chars, char)
+			continue
+		}
+
+		value, rest = string(chars), src[idx+1:]
+		if quote == '"' {
+			value, err = InterpolateCompose(composeUnescape(value), p.lookup)
+		}
+		return
+	}
+
+	first, _, _ := strings.Cut(src, "\n")
+	err = fmt.Errorf("%w: line %d: unterminated quoted value %s", ErrEnvFileSyntax, p.line, first)
+	return
+}
+
+// composeUnescape expands the composeEscapes, turning "\$" into "$$" for
+// InterpolateCompose to produce a literal dollar sign
+func composeUnescape(value string) (unescaped string) {
+	unescaped = composeEscapes.ReplaceAllStringFunc(value, func(match string) string {
+		if match == `\$` {
+			return "$$"
+		}
+		if strings.HasPrefix(match, `\0`) {
+			// octal escapes are written "\0123" and Go expects "\123"
+			match = `\` + match[2:]
+		}
+		if v, _, _, ee := strconv.UnquoteChar(match, '"'); ee == nil {
+			return string(v)
+		}
+		return match
+	})
+	return
+}
+
+// composeSpace reports whether the rune is whitespace other than a newline
+func composeSpace(r rune) (space bool) {
+	switch r {
+	case '\t', '\v', '\f', '\r', ' ', 0x85, 0xA0:
+		space = true
+	}
+	return
+}
+
+// InterpolateCompose replaces the variable references in the `input` using
+// the Docker Compose syntax, with values from the `lookup` function:
+//
+//	$$               a literal "$"
+//	$VAR, ${VAR}     the value of VAR, empty when not set
+//	${VAR:-default}  the default when VAR is not set or empty
+//	${VAR-default}   the default when VAR is not set
+//	${VAR:?message}  an ErrRequiredVariable when VAR is not set or empty
+//	${VAR?message}   an ErrRequiredVariable when VAR is not set
+//	${VAR:+other}    the other when VAR is set and not empty, else empty
+//	${VAR+other}     the other when VAR is set, else empty
+//
+// Defaults, messages and others are interpolated in turn. A "$" not
+// followed by "$", "{" or a variable name is kept as-is and an invalid or
+// unterminated "${" reference is an ErrEnvFileSyntax
+func InterpolateCompose(input string, lookup func(key string) (value string, present bool)) (output string, err error) {
+	var buf strings.Builder
+	for idx := 0; idx < len(input); idx++ {
+		if input[idx] != '$' || idx+1 >= len(input) {
+			buf.WriteByte(input[idx])
+			continue
+		}
+		next := input[idx+1]
+		switch {
+		case next == '$':
+			buf.WriteByte('$')
+			idx += 1
+		case next == '{':
+			end := composeClosingBrace(input[idx:])
+			if end < 0 {
+				err = fmt.Errorf("%w: unterminated reference: %q", ErrEnvFileSyntax, input[idx:])
+				return
+			}
+			var value string
+			if value, err = composeBraced(input[idx+2:idx+end], lookup); err != nil {
+				return
+			}
+			buf.WriteString(value)
+			idx += end
+		case composeNameStart(next):
+			name := composeName(input[idx+1:])
+			value, _ := lookup(name)
+			buf.WriteString(value)
+			idx += len(name)
+		default:
+			buf.WriteByte('$')
+		}
+	}
+	output = buf.String()
+	return
+}
+
+// composeClosingBrace returns the index of the "}" closing the "${" at the
+// start of the `input`, counting any nested "${" references
+func composeClosingBrace(input string) (idx int) {
+	var open int
+	for idx = 0; idx < len(input); idx++ {
+		if input[idx] == '}' {
+			if open -= 1; open == 0 {
+				return
+			}
+		}
+		if strings.HasPrefix(input[idx:], "${") {
+			open += 1
+			idx += 1
+		}
+	}
+	idx = -1
+	return
+}
+
+// composeBraced substitutes the contents of a "${...}" reference
+func composeBraced(reference string, lookup func(key string) (value string, present bool)) (value string, err error) {
+	name := composeName(reference)
+	if name == "" || !composeNameStart(name[0]) {
+		err = fmt.Errorf("%w: invalid reference: %q", ErrEnvFileSyntax, "${"+reference+"}")
+		return
+	}
+	modifier := reference[len(name):]
+	current, present := lookup(name)
+	if modifier == "" {
+		value = current
+		return
+	}
+
+	colon := modifier[0] == ':'
+	if colon {
+		modifier = modifier[1:]
+	}
+	if modifier == "" || strings.IndexByte("-?+", modifier[0]) < 0 {
+		err = fmt.Errorf("%w: invalid reference: %q", ErrEnvFileSyntax, "${"+reference+"}")
+		return
+	}
+	operator, argument := modifier[0], modifier[1:]
+	// set is whether the variable counts as set for the operator
+	set := present && (!colon || current != "")
+
+	switch operator {
+	case '-':
+		if value = current; !set {
+			value, err = InterpolateCompose(argument, lookup)
+		}
+	case '?':
+		if value = current; !set {
+			var message string
+			if message, err = InterpolateCompose(argument, lookup); err == nil {
+				err = fmt.Errorf("%w: %s", ErrRequiredVariable, name)
+				if message != "" {
+					err = fmt.Errorf("%w: %s: %s", ErrRequiredVariable, name, message)
+				}
+			}
+		}
+	case '+':
+		if set {
+			value, err = InterpolateCompose(argument, lookup)
+		}
+	}
+	return
+}
+
+func composeNameStart(c byte) (ok bool) {
+	ok = c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+	return
+}
+
+// composeName returns the variable name at the start of the `input`
+func composeName(input string) (name string) {
+	var idx int
+	for idx = 0; idx < len(input); idx++ {
+		if c := input[idx]; !composeNameStart(c) && (c < '0' || c > '9') {
+			break
+		}
+	}
+	name = input[:idx]
+	return
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompose(t *testing.T) {
+	Convey("Env.ParseComposeEnvFile", t, func() {
+		host := New()
+		host.Set("HOST", "host")
+		host.Set("EMPTY", "")
+
+		for idx, test := range []struct {
+			input  string
+			output []string
+			err    error
+		}{
+			{
+				input:  "A=plain\nB = spaced \nexport C=exported\nexport\tD=tab\nD2: yaml\nexportE=1\n",
+				output: []string{"A=plain", "B=spaced", "C=exported", "D=tab", "D2=yaml", "exportE=1"},
+			},
+			{
+				input: "# comment\nA=value # comment\nB=value#not\nC='single # kept'\nD=\"double # kept\"\n",
+				output: []string{
+					"A=value", "B=value#not", "C=single # kept", "D=double # kept",
+				},
+			},
+			{
+				input: "A='$HOST \\n'\nB=\"$HOST\\tx\\$HOST\\\\\"\nC=\"multi\nline\\nthree\"\n" +
+					"D=\"say \\\"hi\\\"\"\nE='it\\'s'\nF=\"\\0101\"\n",
+				output: []string{
+					"A=$HOST \\n", "B=host\tx$HOST\\", "C=multi\nline\nthree",
+					"D=say \"hi\"", "E=it's", "F=A",
+				},
+			},
+			{
+				input: "LOCAL=file\nHOST=file-host\nA=${HOST}\nB=$HOST-x\nC=${MISSING:-def}\n" +
+					"D=${EMPTY:-def}\nE=${EMPTY-def}\nF=${MISSING-def}\nG=${HOST:+alt}\n" +
+					"H=${EMPTY:+alt}\nI=${EMPTY+alt}\nJ=$$HOST\nK=${LOCAL}/${MISSING:-${LOCAL}}\n" +
+					"L=cost $5 and $\nM=${EMPTY?}\n",
+				output: []string{
+					"LOCAL=file", "HOST=file-host", "A=host", "B=host-x", "C=def",
+					"D=def", "E=", "F=def", "G=alt",
+					"H=", "I=alt", "J=$HOST", "K=file/file",
+					"L=cost $5 and $", "M=",
+				},
+			},
+			{
+				input:  "HOST\nMISSING\n  EMPTY\nLAST",
+				output: []string{"HOST=host", "EMPTY="},
+			},
+			{
+				input:  "\xef\xbb\xbfA=1\r\nB=\"2\"\r\n",
+				output: []string{"A=1", "B=2"},
+			},
+			{
+				input: "A=${MISSING:?set it}\n",
+				err:   ErrRequiredVariable,
+			},
+			{
+				input: "A=${EMPTY:?}\n",
+				err:   ErrRequiredVariable,
+			},
+			{
+				input: "A=${MISSING?}\n",
+				err:   ErrRequiredVariable,
+			},
+			{
+				input: "A=${HOST\n",
+				err:   ErrEnvFileSyntax,
+			},
+			{
+				input: "A=${1BAD}\n",
+				err:   ErrEnvFileSyntax,
+			},
+			{
+				input: "A=${HOST/x}\n",
+				err:   ErrEnvFileSyntax,
+			},
+			{
+				input: "A B=1\n",
+				err:   ErrEnvFileSyntax,
+			},
+			{
+				input: "A!=1\n",
+				err:   ErrEnvFileSyntax,
+			},
+			{
+				input: "A=\"open\nB=2\n",
+				err:   ErrEnvFileSyntax,
+			},
+		} {
+			Convey(fmt.Sprintf("case %d: %q", idx, test.input), func() {
+				parsed, err := host.ParseComposeEnvFile(strings.NewReader(test.input))
+				if test.err != nil {
+					So(err, ShouldWrap, test.err)
+					So(parsed, ShouldBeNil)
+					return
+				}
+				So(err, ShouldBeNil)
+				So(parsed.Environ(), ShouldEqual, test.output)
+			})
+		}
+
+		_, err := host.ParseComposeEnvFile(strings.NewReader("A=${MISSING:?set it in .env}\n"))
+		So(err.Error(), ShouldEqual, "required variable is missing a value: MISSING: set it in .env")
+	})
+
+	Convey("InterpolateCompose", t, func() {
+		lookup := func(key string) (string, bool) {
+			if key == "NAME" {
+				return "world", true
+			}
+			return "", false
+		}
+		output, err := InterpolateCompose("hello ${NAME:-${OTHER:-x}} $NAME$$ ${MISSING:+y}", lookup)
+		So(err, ShouldBeNil)
+		So(output, ShouldEqual, "hello world world$ ")
+		output, err = InterpolateCompose("${MISSING:-${OTHER:-nested}}", lookup)
+		So(err, ShouldBeNil)
+		So(output, ShouldEqual, "nested")
+		_, err = InterpolateCompose("${}", lookup)
+		So(err, ShouldWrap, ErrEnvFileSyntax)
+	})
+}
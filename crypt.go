@@ -0,0 +1,230 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// EncryptedPrefix is the start of all encrypted values
+	EncryptedPrefix = "ENC[aes256gcm,"
+	// EncryptedSuffix is the end of all encrypted values
+	EncryptedSuffix = "]"
+)
+
+var (
+	ErrInvalidKey   = errors.New("encryption key must be 32 bytes")
+	ErrNotEncrypted = errors.New("value is not encrypted")
+	ErrDecrypt      = errors.New("decryption failed")
+)
+
+// ParseKey decodes the base64 encoded `text` into a 32 byte AES-256 key,
+// surrounding whitespace is ignored. A suitable key can be made with:
+//
+//	head -c 32 /dev/urandom | base64
+func ParseKey(text string) (key []byte, err error) {
+	if key, err = base64.StdEncoding.DecodeString(strings.TrimSpace(text)); err != nil {
+		err = fmt.Errorf("%w: %w", ErrInvalidKey, err)
+		return
+	} else if len(key) != 32 {
+		key, err = nil, ErrInvalidKey
+	}
+	return
+}
+
+// ReadKeyFile is a convenience wrapper around os.ReadFile and ParseKey
+func ReadKeyFile(path string) (key []byte, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); err == nil {
+		key, err = ParseKey(string(data))
+	}
+	return
+}
+
+// KeyFromEnv is a convenience wrapper around Env.Lookup and ParseKey. Note
+// that file indirection and resolvers apply to the `name` lookup
+func KeyFromEnv(e Env, name string) (key []byte, err error) {
+	var text string
+	var present bool
+	if text, present, err = e.Lookup(name); err != nil {
+		return
+	} else if !present {
+		err = fmt.Errorf("%w: %s", ErrNotPresent, name)
+		return
+	}
+	key, err = ParseKey(text)
+	return
+}
+
+// IsEncrypted reports whether the `value` is in the form produced by
+// EncryptValue
+func IsEncrypted(value string) (encrypted bool) {
+	encrypted = strings.HasPrefix(value, EncryptedPrefix) && strings.HasSuffix(value, EncryptedSuffix)
+	return
+}
+
+// EncryptValue uses AES-256-GCM to encrypt the `plaintext` with the given
+// `key`, returning a value of the form "ENC[aes256gcm,<base64>]". The
+// variable `name` is authenticated along with the value, so that the value
+// only decrypts for the same `name`
+func EncryptValue(key []byte, name, plaintext string) (value string, err error) {
+	var aead cipher.AEAD
+	if aead, err = newAEAD(key); err != nil {
+		return
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(name))
+	value = EncryptedPrefix + base64.StdEncoding.EncodeToString(sealed) + EncryptedSuffix
+	return
+}
+
+// DecryptValue is the inverse of EncryptValue, failing with ErrDecrypt when
+// the `name` is not the one the value was encrypted for
+func DecryptValue(key []byte, name, value string) (plaintext string, err error) {
+	if !IsEncrypted(value) {
+		err = ErrNotEncrypted
+		return
+	}
+	var aead cipher.AEAD
+	if aead, err = newAEAD(key); err != nil {
+		return
+	}
+	encoded := value[len(EncryptedPrefix) : len(value)-len(EncryptedSuffix)]
+	var sealed, opened []byte
+	if sealed, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+		err = fmt.Errorf("%w: %w", ErrDecrypt, err)
+		return
+	} else if len(sealed) < aead.NonceSize() {
+		err = fmt.Errorf("%w: value too short", ErrDecrypt)
+		return
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	if opened, err = aead.Open(nil, nonce, ciphertext, []byte(name)); err != nil {
+		err = fmt.Errorf("%w: %w", ErrDecrypt, err)
+		return
+	}
+	plaintext = string(opened)
+	return
+}
+
+// DecryptEnv replaces all encrypted values within the Env with their
+// plaintext, typically used right after importing an environment file. No
+// changes are made if any value fails to decrypt
+func DecryptEnv(e Env, key []byte) (err error) {
+	decrypted := make(map[string]string)
+	for _, variable := range e.Environ() {
+		if name, value, _ := strings.Cut(variable, "="); IsEncrypted(value) {
+			if decrypted[name], err = DecryptValue(key, name, value); err != nil {
+				err = fmt.Errorf("%s: %w", name, err)
+				return
+			}
+		}
+	}
+	for name, value := range decrypted {
+		e.Set(name, value)
+	}
+	return
+}
+
+// EncryptEnv returns a clone of the Env with the values of all secret keys
+// (see Env.MarkSecret) encrypted, typically used right before writing an
+// environment file. Values already encrypted are left as-is
+func EncryptEnv(e Env, key []byte) (encrypted Env, err error) {
+	encrypted = e.Clone()
+	for _, variable := range e.Environ() {
+		if name, value, _ := strings.Cut(variable, "="); e.IsSecret(name) && !IsEncrypted(value) {
+			if value, err = EncryptValue(key, name, value); err != nil {
+				encrypted = nil
+				return
+			}
+			encrypted.Set(name, value)
+		}
+	}
+	return
+}
+
+// FileOption configures the environment file loaders and writers
+type FileOption func(o *fileOptions)
+
+type fileOptions struct {
+	key []byte
+}
+
+// WithEncryptionKey enables automatic encryption with the given `key`.
+// Loaders decrypt all encrypted values read from the file and mark those
+// variables secret, writers encrypt the values of all secret variables
+// which are not encrypted already
+func WithEncryptionKey(key []byte) (option FileOption) {
+	option = func(o *fileOptions) {
+		o.key = key
+	}
+	return
+}
+
+func newFileOptions(options []FileOption) (o *fileOptions) {
+	o = &fileOptions{}
+	for _, option := range options {
+		option(o)
+	}
+	return
+}
+
+// load returns the value to store for the `name` variable read by a loader
+func (o *fileOptions) load(e Env, name, value string) (loaded string, err error) {
+	if loaded = value; o.key == nil || !IsEncrypted(value) {
+		return
+	}
+	if loaded, err = DecryptValue(o.key, name, value); err != nil {
+		err = fmt.Errorf("%s: %w", name, err)
+		return
+	}
+	e.MarkSecret(name)
+	return
+}
+
+// write returns the value a writer outputs for the `name` variable
+func (o *fileOptions) write(secret bool, name, value string) (written string, err error) {
+	if written = value; o.key == nil || !secret || IsEncrypted(value) {
+		return
+	}
+	if written, err = EncryptValue(o.key, name, value); err != nil {
+		err = fmt.Errorf("%s: %w", name, err)
+	}
+	return
+}
+
+func newAEAD(key []byte) (aead cipher.AEAD, err error) {
+	if len(key) != 32 {
+		err = ErrInvalidKey
+		return
+	}
+	var block cipher.Block
+	if block, err = aes.NewCipher(key); err != nil {
+		return
+	}
+	aead, err = cipher.NewGCM(block)
+	return
+}
@@ -16,6 +16,8 @@
 package env
 
 import (
+	"context"
+	"io"
 	"os"
 )
 
@@ -107,3 +109,45 @@ func String(key string, def string) (value string) {
 	value = _env.String(key, def)
 	return
 }
+
+// TrackAccess is a wrapper around the Default Env.TrackAccess
+func TrackAccess(enabled bool) {
+	_env.TrackAccess(enabled)
+	return
+}
+
+// GetAccessReport is a wrapper around the Default Env.AccessReport
+func GetAccessReport() (report AccessReport) {
+	report = _env.AccessReport()
+	return
+}
+
+// Source is a wrapper around the Default Env.Source
+func Source(ctx context.Context, script, shell string) (sourced Env, err error) {
+	sourced, err = _env.Source(ctx, script, shell)
+	return
+}
+
+// LoginShell is a wrapper around the Default Env.LoginShell
+func LoginShell(ctx context.Context) (login Env, err error) {
+	login, err = _env.LoginShell(ctx)
+	return
+}
+
+// ParseDockerEnvFile is a wrapper around the Default Env.ParseDockerEnvFile
+func ParseDockerEnvFile(r io.Reader, options ...FileOption) (parsed Env, err error) {
+	parsed, err = _env.ParseDockerEnvFile(r, options...)
+	return
+}
+
+// ParseComposeEnvFile is a wrapper around the Default Env.ParseComposeEnvFile
+func ParseComposeEnvFile(r io.Reader, options ...FileOption) (parsed Env, err error) {
+	parsed, err = _env.ParseComposeEnvFile(r, options...)
+	return
+}
+
+// LookPath is a wrapper around the Default Env.LookPath
+func LookPath(name string) (path string, err error) {
+	path, err = _env.LookPath(name)
+	return
+}
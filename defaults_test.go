@@ -15,6 +15,7 @@
 package env
 
 import (
+	"strings"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -43,6 +44,16 @@ func TestDefaults(t *testing.T) {
 		So(Int("coreutils_env_test", 10), ShouldEqual, 1)
 		So(Float("coreutils_env_test", 1.1), ShouldEqual, 1.0)
 		So(String("coreutils_env_test", "one"), ShouldEqual, "1")
+		TrackAccess(true)
+		So(Int("coreutils_env_test", 10), ShouldEqual, 1)
+		So(GetAccessReport().Reads, ShouldEqual, map[string]int{"coreutils_env_test": 1})
+		TrackAccess(false)
+		parsed, err := ParseDockerEnvFile(strings.NewReader("coreutils_env_test\n"))
+		So(err, ShouldBeNil)
+		So(parsed.Environ(), ShouldEqual, []string{"coreutils_env_test=1"})
+		parsed, err = ParseComposeEnvFile(strings.NewReader("copy=${coreutils_env_test}\n"))
+		So(err, ShouldBeNil)
+		So(parsed.Environ(), ShouldEqual, []string{"copy=1"})
 		Clear() // do this last
 		So(Len(), ShouldEqual, 0)
 		So(Export(), ShouldBeNil)
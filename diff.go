@@ -0,0 +1,101 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"strings"
+)
+
+// ChangeOp is the kind of difference a Change describes
+type ChangeOp uint8
+
+const (
+	Added ChangeOp = iota + 1
+	Removed
+	Modified
+)
+
+func (op ChangeOp) String() (name string) {
+	switch op {
+	case Added:
+		name = "added"
+	case Removed:
+		name = "removed"
+	case Modified:
+		name = "modified"
+	}
+	return
+}
+
+// Change describes the difference of one variable between two Env
+// instances. Old is empty when Added and New is empty when Removed
+type Change struct {
+	Op     ChangeOp
+	Key    string
+	Old    string
+	New    string
+	Secret bool
+}
+
+// String returns the Change in one of the following forms, with secret
+// values replaced by the RedactedValue:
+//
+//	+KEY=new
+//	-KEY=old
+//	~KEY=old -> new
+func (c Change) String() (text string) {
+	older, newer := c.Old, c.New
+	if c.Secret {
+		older, newer = RedactedValue, RedactedValue
+	}
+	switch c.Op {
+	case Added:
+		text = "+" + c.Key + "=" + newer
+	case Removed:
+		text = "-" + c.Key + "=" + older
+	case Modified:
+		text = "~" + c.Key + "=" + older + " -> " + newer
+	}
+	return
+}
+
+func (c *cEnv) Diff(other Env) (changes []Change) {
+	current := c.Environ()
+	updated := other.Environ()
+	lookup := make(map[string]string, len(updated))
+	for _, variable := range updated {
+		key, value, _ := strings.Cut(variable, "=")
+		lookup[key] = value
+	}
+	seen := make(map[string]struct{}, len(current))
+	for _, variable := range current {
+		key, value, _ := strings.Cut(variable, "=")
+		seen[key] = struct{}{}
+		secret := c.IsSecret(key) || other.IsSecret(key)
+		if newer, present := lookup[key]; !present {
+			changes = append(changes, Change{Op: Removed, Key: key, Old: value, Secret: secret})
+		} else if newer != value {
+			changes = append(changes, Change{Op: Modified, Key: key, Old: value, New: newer, Secret: secret})
+		}
+	}
+	for _, variable := range updated {
+		key, value, _ := strings.Cut(variable, "=")
+		if _, present := seen[key]; !present {
+			secret := c.IsSecret(key) || other.IsSecret(key)
+			changes = append(changes, Change{Op: Added, Key: key, New: value, Secret: secret})
+		}
+	}
+	return
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDiff(t *testing.T) {
+	Convey("Env.Diff", t, func() {
+		env := New()
+		env.Set("same", "value")
+		env.Set("gone", "value")
+		env.Set("changed", "before")
+		env.Set("DB_PASSWORD", "hunter2")
+		other := env.Clone()
+		other.Unset("gone")
+		other.Set("changed", "after")
+		other.Set("DB_PASSWORD", "hunter3")
+		other.Set("new", "value")
+		other.Set("DSN", "postgres://")
+		other.MarkSecret("DSN")
+
+		changes := env.Diff(other)
+		So(changes, ShouldEqual, []Change{
+			{Op: Removed, Key: "gone", Old: "value"},
+			{Op: Modified, Key: "changed", Old: "before", New: "after"},
+			{Op: Modified, Key: "DB_PASSWORD", Old: "hunter2", New: "hunter3", Secret: true},
+			{Op: Added, Key: "new", New: "value"},
+			{Op: Added, Key: "DSN", New: "postgres://", Secret: true},
+		})
+		So(fmt.Sprint(changes), ShouldEqual, "[-gone=value ~changed=before -> after ~DB_PASSWORD=*** -> *** +new=value +DSN=***]")
+		So(env.Diff(env.Clone()), ShouldBeNil)
+		So(Added.String(), ShouldEqual, "added")
+		So(Removed.String(), ShouldEqual, "removed")
+		So(Modified.String(), ShouldEqual, "modified")
+		So(ChangeOp(0).String(), ShouldEqual, "")
+		So(Change{}.String(), ShouldEqual, "")
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrEnvFileSyntax = errors.New("env file syntax error")
+
+// utf8BOM is removed from the start of env files
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// ParseDockerEnvFile reads the `docker run --env-file` format, following the
+// Docker CLI parseKeyValueFile function, and returns the variables the
+// container is given, using this Env as the host environment:
+//
+//   - a leading UTF-8 byte order mark and leading whitespace are removed
+//   - empty lines and lines starting with "#" are skipped
+//   - "KEY=value" lines use the value exactly, without any quote
+//     processing, comment removal or trimming
+//   - "KEY" lines pass the host value through, or are skipped when the host
+//     does not have the KEY
+//   - a KEY containing spaces or tabs, or an empty KEY, is an error
+//
+// Input which is not valid UTF-8 is an error. See WithEncryptionKey for
+// decrypting the values read from the file
+func (c *cEnv) ParseDockerEnvFile(r io.Reader, options ...FileOption) (parsed Env, err error) {
+	o := newFileOptions(options)
+	found := newEnv()
+	scanner := bufio.NewScanner(r)
+	var number int
+	for scanner.Scan() {
+		data := scanner.Bytes()
+		if number += 1; number == 1 {
+			data = bytes.TrimPrefix(data, utf8BOM)
+		}
+		if !utf8.Valid(data) {
+			err = fmt.Errorf("%w: line %d", ErrInvalidUTF8, number)
+			return
+		}
+		line := strings.TrimLeftFunc(string(data), unicode.IsSpace)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		key, value, hasValue := strings.Cut(line, "=")
+		if strings.ContainsAny(key, " \t") {
+			err = fmt.Errorf("%w: line %d: variable %q contains whitespaces", ErrEnvFileSyntax, number, key)
+			return
+		} else if key == "" {
+			err = fmt.Errorf("%w: line %d: no variable name", ErrEnvFileSyntax, number)
+			return
+		}
+		if hasValue {
+			if value, err = o.load(found, key, value); err != nil {
+				err = fmt.Errorf("line %d: %w", number, err)
+				return
+			}
+			found.Set(key, value)
+		} else if value, present := c.Get(key); present {
+			found.Set(key, value)
+		}
+	}
+	if err = scanner.Err(); err == nil {
+		parsed = found
+	}
+	return
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDocker(t *testing.T) {
+	Convey("Env.ParseDockerEnvFile", t, func() {
+		host := New()
+		host.Set("HOST_SET", "from-host")
+		host.Set("HOST_EMPTY", "")
+
+		for idx, test := range []struct {
+			input  string
+			output []string
+			err    error
+		}{
+			{
+				input:  "\xef\xbb\xbfFOO=bar\n",
+				output: []string{"FOO=bar"},
+			},
+			{
+				input: "  # comment\n\nA=\"quoted\"\nB='single'\nC=value # not a comment\nD=  spaced  \n",
+				output: []string{
+					"A=\"quoted\"", "B='single'", "C=value # not a comment", "D=  spaced  ",
+				},
+			},
+			{
+				input:  "E=a=b\nF=\n\tG=indented\n",
+				output: []string{"E=a=b", "F=", "G=indented"},
+			},
+			{
+				input:  "HOST_SET\nHOST_MISSING\n  HOST_EMPTY\n",
+				output: []string{"HOST_SET=from-host", "HOST_EMPTY="},
+			},
+			{
+				input:  "A=1\r\nB=$A\r\nA=2",
+				output: []string{"A=2", "B=$A"},
+			},
+			{
+				input: "export A=1\n",
+				err:   ErrEnvFileSyntax,
+			},
+			{
+				input: "KEY\t=value\n",
+				err:   ErrEnvFileSyntax,
+			},
+			{
+				input: "=value\n",
+				err:   ErrEnvFileSyntax,
+			},
+			{
+				input: "A=1\nB=\xff\n",
+				err:   ErrInvalidUTF8,
+			},
+		} {
+			Convey(fmt.Sprintf("case %d: %q", idx, test.input), func() {
+				parsed, err := host.ParseDockerEnvFile(strings.NewReader(test.input))
+				if test.err != nil {
+					So(err, ShouldWrap, test.err)
+					So(parsed, ShouldBeNil)
+					return
+				}
+				So(err, ShouldBeNil)
+				So(parsed.Environ(), ShouldEqual, test.output)
+			})
+		}
+	})
+}
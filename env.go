@@ -15,8 +15,15 @@
 package env
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
 	"maps"
+	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
 	"strconv"
 	"strings"
 	"sync"
@@ -32,6 +39,11 @@ var _ Env = (*cEnv)(nil)
 // Env is an abstraction around the standard os package env related things
 // with some conveniences added like Env.Int and Env.Float
 type Env interface {
+	// Format implements fmt.Formatter, printing the Redacted variables
+	fmt.Formatter
+	// LogValue implements slog.LogValuer, grouping the Redacted variables
+	slog.LogValuer
+
 	// Len returns the number of variables present
 	Len() (count int)
 	// Clear deletes all Env variables
@@ -53,19 +65,26 @@ type Env interface {
 	Import(environment []string)
 	// Include applies all variables within the others to this Env instance.
 	// Note that keys are not deleted and any existing keys are clobbered by
-	// the others, in the order the others are given
+	// the others, in the order the others are given. Values are copied
+	// exactly, without the quote trimming of Import
 	Include(others ...Env)
 	// WriteEnvDir makes the given directory path if it doesn't exist already
 	// and then for each key/value pair in the Env, creates a file named with
-	// the key and the value as the contents
-	WriteEnvDir(path string) (err error)
+	// the key and the value as the contents. See WithEncryptionKey for
+	// encrypting the secret values written
+	WriteEnvDir(path string, options ...FileOption) (err error)
 	// Expand replaces all `$key` and `${key}` references in the `input` string
 	// with their corresponding `key` values. Any references not present within
 	// the Env are replaced with empty strings
 	Expand(input string) (expanded string)
 	// Get looks for the variable `key` and if `present` returns the exact
-	// `value`
+	// `value`. Get is Lookup with any error reported as not present
 	Get(key string) (value string, present bool)
+	// Lookup is the error reporting form of Get. An error is returned when
+	// file indirection is enabled (see ResolveFiles) and the `key_FILE`
+	// cannot be read, or when a registered Resolver fails (see
+	// RegisterResolver)
+	Lookup(key string) (value string, present bool, err error)
 	// Set updates the Env `key` with the given `value`
 	Set(key, value string)
 	// Unset removes the `key` from the Env
@@ -85,6 +104,152 @@ type Env interface {
 	// String uses strings.TrimSpace to transform the value associated with
 	// `key` and if not present, returns `def`
 	String(key string, def string) (value string)
+
+	// TrackAccess enables or disables the recording of variable reads made
+	// through Get and the typed accessors (Bool, Int, Float and String).
+	// Enabling an already tracking Env keeps the counts recorded so far and
+	// disabling discards them
+	TrackAccess(enabled bool)
+	// AccessReport returns a snapshot of the reads recorded since access
+	// tracking was enabled. The report is empty when not tracking
+	AccessReport() (report AccessReport)
+
+	// MarkSecret flags the given keys as sensitive, causing their values to
+	// be redacted when the Env is dumped, printed or logged. Get and the
+	// typed accessors still return the real values
+	MarkSecret(keys ...string)
+	// MarkSecretPattern flags all keys matching any of the given path.Match
+	// patterns as sensitive. New Env instances start with the
+	// DefaultSecretPatterns
+	MarkSecretPattern(patterns ...string) (err error)
+	// IsSecret reports whether the given key is flagged as sensitive, either
+	// explicitly or by pattern
+	IsSecret(key string) (secret bool)
+	// Redacted is the same as Environ except that the values of all secret
+	// keys are replaced with the RedactedValue
+	Redacted() (variables []string)
+
+	// ResolveFiles enables or disables `_FILE` indirection. When enabled,
+	// looking up an unset `key` reads the contents of the file named by the
+	// `key_FILE` variable, with one trailing newline removed. Files larger
+	// than MaxIndirectFileSize and having both variables set are errors
+	ResolveFiles(enabled bool)
+	// RegisterResolver adds, replaces or, when `resolver` is nil, removes the
+	// Resolver for values of the form "scheme:reference". Values are resolved
+	// when looked up and successful results are cached until the next change
+	// to the Env variables or resolvers
+	RegisterResolver(scheme string, resolver Resolver)
+
+	// With returns a clone of the Env with the given "key=value" `overrides`
+	// applied, without any quote trimming. Inputs missing the equal sign are
+	// ignored
+	With(overrides ...string) (env Env)
+	// Only returns a clone of the Env with only the given `keys` present
+	Only(keys ...string) (env Env)
+	// Command is like exec.CommandContext except that the `name` is found
+	// using the Env PATH instead of the process PATH and the command
+	// environment is exactly this Env. A nil `ctx` is allowed and lookup
+	// errors are reported by the exec.Cmd when run
+	Command(ctx context.Context, name string, args ...string) (cmd *exec.Cmd)
+	// Exec replaces the current process with the `argv` command, found
+	// using the Env PATH, and with exactly this Env as its environment.
+	// Exec only returns on error and is not supported on all platforms
+	Exec(argv []string) (err error)
+
+	// Sanitize returns a clone of the Env with only the variables allowed by
+	// the `policy`, along with the reasons for each variable `dropped`
+	Sanitize(policy Policy) (clean Env, dropped []Dropped)
+
+	// Diff compares this Env with the `other`, returning the variables
+	// removed or modified, in this Env's order, followed by the variables
+	// added, in the other's order
+	Diff(other Env) (changes []Change)
+	// Source runs the `script` with the `shell` (DefaultShell when empty)
+	// within this Env and returns a clone with the variables present after
+	// the script completed. Anything the script prints to stdout is
+	// redirected to stderr. A `script` without a slash is found in the
+	// current directory, not the PATH. The `shell` must support the "."
+	// builtin and the env command must support the -0 flag
+	Source(ctx context.Context, script, shell string) (sourced Env, err error)
+	// LoginShell runs the SHELL (DefaultLoginShell when not set) as a login
+	// shell within this Env and returns a clone with the variables present
+	// in the login shell, ready to be Included into another Env, which keeps
+	// the values exactly. Anything the shell profiles print is ignored and
+	// the LoginShellTimeout applies when the `ctx` is nil or has no deadline
+	LoginShell(ctx context.Context) (login Env, err error)
+
+	// ImportNUL reads NUL separated "key=value" entries, the format of
+	// `env -0` and /proc/<pid>/environ, and updates the Env with them once
+	// the reader is exhausted. Values are used exactly, without the quote
+	// trimming of Import, and the input is limited by MaxNULEntrySize and
+	// MaxNULSize
+	ImportNUL(r io.Reader) (err error)
+	// WriteNUL writes all variables as NUL terminated "key=value" entries,
+	// returning an error without writing anything if any key contains an
+	// equal sign or NUL byte, or any value contains a NUL byte
+	WriteNUL(w io.Writer) (err error)
+
+	// LookPath is like exec.LookPath except that the Env PATH is searched
+	// instead of the process PATH. Names containing a path separator are
+	// checked directly and empty PATH entries are the current directory
+	LookPath(name string) (path string, err error)
+	// LookPathAll returns all of the executable `name` files found in the
+	// Env PATH, in order. The first path is the one LookPath and Command
+	// use, any others are shadowed by it
+	LookPathAll(name string) (paths []string)
+
+	// XDG returns the XDG Base Directory locations, with the specification
+	// defaults applied for unset, empty or relative values
+	XDG() (xdg XDG)
+
+	// Locale returns the locale in effect for the given `category`, such as
+	// LcMessages or "LC_MESSAGES", following the POSIX precedence of LC_ALL
+	// over the category variable over LANG, defaulting to the "C" locale
+	Locale(category string) (locale Locale)
+
+	// Terminal returns the terminal capabilities described by the TERM,
+	// COLORTERM, TERM_PROGRAM, COLUMNS and LINES variables, along with the
+	// colour preferences of the NO_COLOR, FORCE_COLOR, CLICOLOR and
+	// CLICOLOR_FORCE conventions
+	Terminal() (terminal Terminal)
+
+	// ProxyFunc returns a function suitable for http.Transport.Proxy which
+	// uses the HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables, or their
+	// lowercase forms, of this Env with the same semantics as
+	// golang.org/x/net/http/httpproxy. When REQUEST_METHOD is set, as in CGI
+	// environments, HTTP_PROXY is refused with an error for http requests.
+	// The variables are read once, when ProxyFunc is called
+	ProxyFunc() (proxy func(req *http.Request) (*url.URL, error))
+
+	// CI returns the continuous integration provider and the branch,
+	// commit, pull request and job details it describes in this Env
+	CI() (ci CI)
+
+	// WriteGitHubEnv writes all variables in the format of the files named
+	// by GITHUB_ENV and GITHUB_OUTPUT. Multi-line values use the
+	// "KEY<<DELIMITER" form with a random delimiter not found in the key or
+	// value. An error is returned without writing anything if any key is
+	// empty or contains an equal sign, "<<" or a line break
+	WriteGitHubEnv(w io.Writer) (err error)
+
+	// WriteSystemdEnv writes all variables in the EnvironmentFile= format
+	// of systemd, read by ParseSystemdEnv. Values with whitespace, control
+	// or shell special characters are double quoted, with any of "\"\\`$"
+	// backslash escaped. An error is returned without writing anything if
+	// any key is not a valid variable name or any value contains a NUL byte.
+	// See WithEncryptionKey for encrypting the secret values written
+	WriteSystemdEnv(w io.Writer, options ...FileOption) (err error)
+
+	// ParseDockerEnvFile reads the `docker run --env-file` format and
+	// returns the variables defined, with this Env as the host environment
+	// for KEY-only lines. See WithEncryptionKey for decrypting the values
+	// read from the file
+	ParseDockerEnvFile(r io.Reader, options ...FileOption) (parsed Env, err error)
+	// ParseComposeEnvFile reads the Docker Compose `.env` format and returns
+	// the variables defined, with this Env as the host environment for
+	// KEY-only lines and interpolation. See WithEncryptionKey for decrypting
+	// the values read from the file, before they are used for interpolation
+	ParseComposeEnvFile(r io.Reader, options ...FileOption) (parsed Env, err error)
 }
 
 // New constructs a new Env instance with no variables present
@@ -103,17 +268,27 @@ func NewImport(environ []string) (env Env) {
 
 func newEnv() (env *cEnv) {
 	env = &cEnv{
-		data:  make(map[string]string),
-		order: make([]string, 0),
-		m:     &sync.RWMutex{},
+		data:     make(map[string]string),
+		order:    make([]string, 0),
+		secrets:  make(map[string]struct{}),
+		patterns: slices.Copy(DefaultSecretPatterns),
+		schemes:  make(map[string]Resolver),
+		resolved: newResolved(),
+		m:        &sync.RWMutex{},
 	}
 	return
 }
 
 type cEnv struct {
-	data  map[string]string
-	order []string
-	m     *sync.RWMutex
+	data     map[string]string
+	order    []string
+	access   *cAccess
+	secrets  map[string]struct{}
+	patterns []string
+	files    bool
+	schemes  map[string]Resolver
+	resolved *cResolved
+	m        *sync.RWMutex
 }
 
 func (c *cEnv) Len() (count int) {
@@ -126,6 +301,7 @@ func (c *cEnv) Clear() {
 	defer c.m.Unlock()
 	c.data = make(map[string]string)
 	c.order = make([]string, 0)
+	c.resolved.flush()
 }
 
 func (c *cEnv) Environ() (variables []string) {
@@ -143,9 +319,18 @@ func (c *cEnv) Clone() (clone Env) {
 	c.m.RLock()
 	defer c.m.RUnlock()
 	cloned := &cEnv{
-		data:  maps.Clone(c.data),
-		order: slices.Copy(c.order),
-		m:     &sync.RWMutex{},
+		data:     maps.Clone(c.data),
+		order:    slices.Copy(c.order),
+		secrets:  maps.Clone(c.secrets),
+		patterns: slices.Copy(c.patterns),
+		files:    c.files,
+		schemes:  maps.Clone(c.schemes),
+		resolved: newResolved(),
+		m:        &sync.RWMutex{},
+	}
+	if c.access != nil {
+		// clones track their own reads, starting from zero
+		cloned.access = newAccess()
 	}
 	clone = cloned
 	return
@@ -175,23 +360,31 @@ func (c *cEnv) Import(environ []string) {
 			}
 		}
 	}
+	c.resolved.flush()
 	return
 }
 
 func (c *cEnv) Include(others ...Env) {
 	for _, other := range others {
-		c.Import(other.Environ())
+		c.setEntries(other.Environ())
 	}
 }
 
-func (c *cEnv) WriteEnvDir(path string) (err error) {
+func (c *cEnv) WriteEnvDir(path string, options ...FileOption) (err error) {
+	o := newFileOptions(options)
 	c.m.RLock()
 	defer c.m.RUnlock()
+	values := make([]string, len(c.order))
+	for idx, key := range c.order {
+		if values[idx], err = o.write(c.isSecret(key), key, c.data[key]); err != nil {
+			return
+		}
+	}
 	if err = os.MkdirAll(path, clpath.DefaultPathPerms); err != nil {
 		return
 	}
-	for _, key := range c.order {
-		if err = os.WriteFile(path+"/"+key, []byte(c.data[key]), clpath.DefaultFilePerms); err != nil {
+	for idx, key := range c.order {
+		if err = os.WriteFile(path+"/"+key, []byte(values[idx]), clpath.DefaultFilePerms); err != nil {
 			return
 		}
 	}
@@ -206,9 +399,47 @@ func (c *cEnv) Expand(input string) (expanded string) {
 }
 
 func (c *cEnv) Get(key string) (value string, present bool) {
+	var err error
+	if value, present, err = c.Lookup(key); err != nil {
+		value, present = "", false
+	}
+	return
+}
+
+func (c *cEnv) Lookup(key string) (value string, present bool, err error) {
+	value, present, err = c.lookup(key, 0)
+	return
+}
+
+func (c *cEnv) lookup(key string, depth int) (value string, present bool, err error) {
 	c.m.RLock()
-	defer c.m.RUnlock()
 	value, present = c.data[key]
+	c.access.record(key)
+	var filename string
+	var indirect bool
+	if c.files {
+		filename, indirect = c.data[key+FileSuffix]
+	}
+	c.m.RUnlock()
+
+	if indirect {
+		if present {
+			err = fmt.Errorf("%w: %s and %s", ErrFileConflict, key, key+FileSuffix)
+			value, present = "", false
+			return
+		}
+		if value, err = readIndirectFile(filename); err != nil {
+			err = fmt.Errorf("%s: %w", key+FileSuffix, err)
+			return
+		}
+		present = true
+	}
+
+	if present {
+		if value, err = c.resolve(key, value, depth); err != nil {
+			value, present = "", false
+		}
+	}
 	return
 }
 
@@ -220,6 +451,7 @@ func (c *cEnv) Set(key, value string) {
 			c.order = append(c.order, key)
 		}
 		c.data[key] = value
+		c.resolved.flush()
 	}
 	return
 }
@@ -230,14 +462,38 @@ func (c *cEnv) Unset(key string) {
 	if _, present := c.data[key]; present {
 		delete(c.data, key)
 		c.order = slices.Prune(c.order, key)
+		c.resolved.flush()
 	}
 	return
 }
 
 func (c *cEnv) Bool(key string, def bool) (state bool) {
-	c.m.RLock()
-	defer c.m.RUnlock()
-	if value, present := c.Get(key); present {
+	value, present := c.Get(key)
+	state = toBool(value, present, def)
+	return
+}
+
+func (c *cEnv) Int(key string, def int) (number int) {
+	value, present := c.Get(key)
+	number = toInt(value, present, def)
+	return
+}
+
+func (c *cEnv) Float(key string, def float64) (decimal float64) {
+	value, present := c.Get(key)
+	decimal = toFloat(value, present, def)
+	return
+}
+
+func (c *cEnv) String(key string, def string) (value string) {
+	v, present := c.Get(key)
+	value = toString(v, present, def)
+	return
+}
+
+// toBool is the Bool transformation of a value from Get
+func toBool(value string, present, def bool) (state bool) {
+	if present {
 		value = strings.TrimSpace(value)
 		if v := clstrings.IsTrue(value); v {
 			state = true
@@ -251,10 +507,9 @@ func (c *cEnv) Bool(key string, def bool) (state bool) {
 	return
 }
 
-func (c *cEnv) Int(key string, def int) (number int) {
-	c.m.RLock()
-	defer c.m.RUnlock()
-	if value, present := c.Get(key); present {
+// toInt is the Int transformation of a value from Get
+func toInt(value string, present bool, def int) (number int) {
+	if present {
 		if v, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
 			number = v
 			return
@@ -264,10 +519,9 @@ func (c *cEnv) Int(key string, def int) (number int) {
 	return
 }
 
-func (c *cEnv) Float(key string, def float64) (decimal float64) {
-	c.m.RLock()
-	defer c.m.RUnlock()
-	if value, present := c.Get(key); present {
+// toFloat is the Float transformation of a value from Get
+func toFloat(value string, present bool, def float64) (decimal float64) {
+	if present {
 		if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
 			decimal = v
 			return
@@ -277,10 +531,9 @@ func (c *cEnv) Float(key string, def float64) (decimal float64) {
 	return
 }
 
-func (c *cEnv) String(key string, def string) (value string) {
-	c.m.RLock()
-	defer c.m.RUnlock()
-	if v, present := c.Get(key); present {
+// toString is the String transformation of a value from Get
+func toString(v string, present bool, def string) (value string) {
+	if present {
 		value = strings.TrimSpace(v)
 		return
 	}
@@ -116,6 +116,12 @@ func TestEnviron(t *testing.T) {
 		So(env0.Environ(), ShouldEqual, []string{
 			"two=thing", "one=more", "another=one",
 		})
+		env3 := newEnv()
+		env3.Set("quoted", `"%s"`)
+		env3.Set("single", "'single'")
+		env0.Include(env3)
+		So(env0.String("quoted", ""), ShouldEqual, `"%s"`)
+		So(env0.String("single", ""), ShouldEqual, "'single'")
 	})
 
 	Convey("WriteEnvDir", t, func() {
@@ -0,0 +1,87 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	clpath "github.com/go-corelibs/path"
+	"github.com/go-corelibs/slices"
+)
+
+// Shadow describes an executable name provided by more than one PATH
+// directory
+type Shadow struct {
+	// Name is the executable file name
+	Name string
+	// Dir is the directory providing the executable which is actually run
+	Dir string
+	// Shadowed lists the later directories also providing the executable
+	Shadowed []string
+}
+
+// Executables returns all executable names found in the PATH directories of
+// the given Env, or the Default Env when nil, along with all the
+// directories providing each, in PATH order. Directories listed more than
+// once are only searched once and empty PATH entries are the current
+// directory
+func Executables(e Env) (found map[string][]string) {
+	found = make(map[string][]string)
+	for _, dir := range pathDirs(e) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if name := entry.Name(); isExecutable(filepath.Join(dir, name)) {
+				found[name] = append(found[name], dir)
+			}
+		}
+	}
+	return
+}
+
+// Shadowed returns the executable names provided by more than one PATH
+// directory of the given Env, or the Default Env when nil, sorted by name
+func Shadowed(e Env) (shadows []Shadow) {
+	for name, dirs := range Executables(e) {
+		if len(dirs) > 1 {
+			shadows = append(shadows, Shadow{
+				Name:     name,
+				Dir:      dirs[0],
+				Shadowed: dirs[1:],
+			})
+		}
+	}
+	sort.Slice(shadows, func(i, j int) bool {
+		return shadows[i].Name < shadows[j].Name
+	})
+	return
+}
+
+// pathDirs returns the unique, existing, PATH directories of the Env
+func pathDirs(e Env) (dirs []string) {
+	for _, dir := range NewPathList(e, "PATH", "").Paths() {
+		if dir == "" {
+			dir = "."
+		}
+		if dir = filepath.Clean(dir); clpath.IsDir(dir) && !slices.Within(dir, dirs) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return
+}
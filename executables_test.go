@@ -0,0 +1,64 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExecutables(t *testing.T) {
+	Convey("Executables and Shadowed", t, func() {
+		tempDir := t.TempDir()
+		for _, dir := range []string{"/a", "/b", "/c", "/c/subdir"} {
+			So(os.MkdirAll(tempDir+dir, 0755), ShouldBeNil)
+		}
+		for file, mode := range map[string]os.FileMode{
+			"/a/go":      0755,
+			"/a/python":  0755,
+			"/a/README":  0644,
+			"/b/go":      0755,
+			"/b/node":    0755,
+			"/c/go":      0700,
+			"/c/node":    0644,
+			"/c/python3": 0755,
+		} {
+			So(os.WriteFile(tempDir+file, []byte("#!/bin/sh\n"), mode), ShouldBeNil)
+		}
+
+		env := New()
+		env.Set("PATH", tempDir+"/a:"+tempDir+"/nope:"+tempDir+"/b:"+tempDir+"/a/:"+tempDir+"/c")
+		So(Executables(env), ShouldEqual, map[string][]string{
+			"go":      {tempDir + "/a", tempDir + "/b", tempDir + "/c"},
+			"python":  {tempDir + "/a"},
+			"node":    {tempDir + "/b"},
+			"python3": {tempDir + "/c"},
+		})
+		So(Shadowed(env), ShouldEqual, []Shadow{
+			{Name: "go", Dir: tempDir + "/a", Shadowed: []string{tempDir + "/b", tempDir + "/c"}},
+		})
+
+		So(os.Chmod(tempDir+"/c/node", 0755), ShouldBeNil)
+		shadows := Shadowed(env)
+		So(len(shadows), ShouldEqual, 2)
+		So(shadows[0].Name, ShouldEqual, "go")
+		So(shadows[1], ShouldEqual, Shadow{Name: "node", Dir: tempDir + "/b", Shadowed: []string{tempDir + "/c"}})
+
+		So(Executables(New()), ShouldEqual, map[string][]string{})
+		So(Shadowed(New()), ShouldBeNil)
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FileSuffix is appended to a key to find the variable naming the file
+// holding the key's value, when file indirection is enabled
+const FileSuffix = "_FILE"
+
+// MaxIndirectFileSize is the largest file, in bytes, that file indirection
+// will read
+var MaxIndirectFileSize int64 = 64 * 1024
+
+var (
+	ErrFileConflict = errors.New("both variable and file variable are set")
+	ErrFileTooLarge = errors.New("file exceeds MaxIndirectFileSize")
+)
+
+func (c *cEnv) ResolveFiles(enabled bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.files = enabled
+}
+
+func readIndirectFile(filename string) (value string, err error) {
+	var fh *os.File
+	if fh, err = os.Open(filename); err != nil {
+		return
+	}
+	defer fh.Close()
+	var data []byte
+	if data, err = io.ReadAll(io.LimitReader(fh, MaxIndirectFileSize+1)); err != nil {
+		return
+	} else if int64(len(data)) > MaxIndirectFileSize {
+		err = fmt.Errorf("%w: %q", ErrFileTooLarge, filename)
+		return
+	}
+	value = strings.TrimSuffix(string(data), "\n")
+	value = strings.TrimSuffix(value, "\r")
+	return
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFiles(t *testing.T) {
+	Convey("Env.ResolveFiles", t, func() {
+		tempDir, err := os.MkdirTemp("", "corelibs-env.*.d")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(tempDir)
+		So(os.WriteFile(tempDir+"/db", []byte("hunter2\n"), 0600), ShouldBeNil)
+		So(os.WriteFile(tempDir+"/workers", []byte("4\r\n"), 0600), ShouldBeNil)
+
+		env := New()
+		env.Set("DB_PASSWORD_FILE", tempDir+"/db")
+		env.Set("WORKERS_FILE", tempDir+"/workers")
+		env.Set("MISSING_FILE", tempDir+"/nope")
+
+		// disabled by default
+		_, present := env.Get("DB_PASSWORD")
+		So(present, ShouldBeFalse)
+
+		env.ResolveFiles(true)
+		value, present, err := env.Lookup("DB_PASSWORD")
+		So(err, ShouldBeNil)
+		So(present, ShouldBeTrue)
+		So(value, ShouldEqual, "hunter2")
+		So(env.String("DB_PASSWORD", ""), ShouldEqual, "hunter2")
+		So(env.Int("WORKERS", 1), ShouldEqual, 4)
+
+		_, present, err = env.Lookup("MISSING")
+		So(err, ShouldNotBeNil)
+		So(errors.Is(err, os.ErrNotExist), ShouldBeTrue)
+		So(present, ShouldBeFalse)
+		So(env.String("MISSING", "default"), ShouldEqual, "default")
+
+		_, present, err = env.Lookup("NOT_A_THING")
+		So(err, ShouldBeNil)
+		So(present, ShouldBeFalse)
+
+		env.Set("DB_PASSWORD", "direct")
+		_, present, err = env.Lookup("DB_PASSWORD")
+		So(errors.Is(err, ErrFileConflict), ShouldBeTrue)
+		So(present, ShouldBeFalse)
+		env.Unset("DB_PASSWORD")
+
+		So(os.WriteFile(tempDir+"/big", []byte(strings.Repeat("x", int(MaxIndirectFileSize)+1)), 0600), ShouldBeNil)
+		env.Set("BIG_FILE", tempDir+"/big")
+		_, _, err = env.Lookup("BIG")
+		So(errors.Is(err, ErrFileTooLarge), ShouldBeTrue)
+
+		cloned := env.Clone()
+		So(cloned.String("DB_PASSWORD", ""), ShouldEqual, "hunter2")
+		env.ResolveFiles(false)
+		_, present = env.Get("DB_PASSWORD")
+		So(present, ShouldBeFalse)
+	})
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	ErrGitHubEnvFormat    = errors.New("invalid github environment file format")
+	ErrGitHubEnvDelimiter = errors.New("github environment file delimiter not found")
+)
+
+// gitHubDelimiter returns a new random heredoc delimiter, it is a variable so
+// that tests can force collisions
+var gitHubDelimiter = func() (delimiter string) {
+	var buf [16]byte
+	_, _ = rand.Read(buf[:])
+	delimiter = "ghadelimiter_" + hex.EncodeToString(buf[:])
+	return
+}
+
+func (c *cEnv) WriteGitHubEnv(w io.Writer) (err error) {
+	c.m.RLock()
+	var entries []string
+	for _, key := range c.order {
+		value := c.data[key]
+		if key == "" || strings.ContainsAny(key, "=\r\n") || strings.Contains(key, "<<") {
+			c.m.RUnlock()
+			err = fmt.Errorf("%w: %q", ErrInvalidVariable, key)
+			return
+		}
+		if !strings.ContainsAny(value, "\r\n") {
+			entries = append(entries, key+"="+value+"\n")
+			continue
+		}
+		delimiter := gitHubDelimiter()
+		for strings.Contains(key, delimiter) || strings.Contains(value, delimiter) {
+			delimiter = gitHubDelimiter()
+		}
+		entries = append(entries, key+"<<"+delimiter+"\n"+value+"\n"+delimiter+"\n")
+	}
+	c.m.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, entry := range entries {
+		if _, err = bw.WriteString(entry); err != nil {
+			return
+		}
+	}
+	err = bw.Flush()
+	return
+}
+
+// WriteGitHubPath writes the `paths` one per line, the format of the file
+// named by GITHUB_PATH, returning an error without writing anything if any
+// path is empty or contains a line break
+func WriteGitHubPath(w io.Writer, paths ...string) (err error) {
+	var buf strings.Builder
+	for _, path := range paths {
+		if path == "" || strings.ContainsAny(path, "\r\n") {
+			err = fmt.Errorf("%w: %q", ErrInvalidVariable, path)
+			return
+		}
+		buf.WriteString(path + "\n")
+	}
+	_, err = io.WriteString(w, buf.String())
+	return
+}
+
+// ParseGitHubEnv reads the format of the files named by GITHUB_ENV and
+// GITHUB_OUTPUT, as written by Env.WriteGitHubEnv. Each line is either a
+// "KEY=value" entry or the start of a "KEY<<DELIMITER" entry, which has the
+// value on the following lines up to a line matching the DELIMITER exactly.
+// Empty lines are skipped and the "KEY=value", "KEY<<DELIMITER" and closing
+// DELIMITER lines may end with "\n" or "\r\n". The lines of a multi-line
+// value are used exactly, keeping any "\r" as written by WriteGitHubEnv
+func ParseGitHubEnv(r io.Reader) (env Env, err error) {
+	parsed := newEnv()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, MaxNULEntrySize)
+	scanner.Split(scanRawLines)
+	var number int
+	next := func() (line string, ok bool) {
+		if ok = scanner.Scan(); ok {
+			number += 1
+			line = scanner.Text()
+		}
+		return
+	}
+
+	for {
+		line, ok := next()
+		if !ok {
+			break
+		} else if line = strings.TrimSuffix(line, "\r"); line == "" {
+			continue
+		}
+		equals, heredoc := strings.Index(line, "="), strings.Index(line, "<<")
+		switch {
+		case equals > 0 && (heredoc < 0 || equals < heredoc):
+			parsed.Set(line[:equals], line[equals+1:])
+		case heredoc > 0 && len(line) > heredoc+2:
+			key, delimiter, start := line[:heredoc], line[heredoc+2:], number
+			var values []string
+			for {
+				if line, ok = next(); !ok {
+					if err = scanner.Err(); err == nil {
+						err = fmt.Errorf("%w: %q on line %d", ErrGitHubEnvDelimiter, delimiter, start)
+					}
+					return
+				} else if strings.TrimSuffix(line, "\r") == delimiter {
+					break
+				}
+				values = append(values, line)
+			}
+			parsed.Set(key, strings.Join(values, "\n"))
+		default:
+			err = fmt.Errorf("%w: line %d", ErrGitHubEnvFormat, number)
+			return
+		}
+	}
+
+	if err = scanner.Err(); err == nil {
+		env = parsed
+	}
+	return
+}
+
+// scanRawLines is bufio.ScanLines without the removal of a trailing "\r"
+func scanRawLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+		advance, token = idx+1, data[:idx]
+	} else if atEOF && len(data) > 0 {
+		advance, token = len(data), data
+	}
+	return
+}
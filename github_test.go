@@ -0,0 +1,132 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGitHub(t *testing.T) {
+	Convey("Env.WriteGitHubEnv", t, func() {
+		env := New()
+		env.Set("SIMPLE", "value")
+		env.Set("EMPTY", "")
+		env.Set("EQUALS", "a=b<<c")
+		env.Set("MULTI", "line one\nline two\n")
+		env.Set("CRLF", "a\r\nb\r")
+
+		var buf bytes.Buffer
+		So(env.WriteGitHubEnv(&buf), ShouldBeNil)
+		lines := strings.Split(buf.String(), "\n")
+		So(lines[:3], ShouldEqual, []string{"SIMPLE=value", "EMPTY=", "EQUALS=a=b<<c"})
+		key, delimiter, found := strings.Cut(lines[3], "<<")
+		So(found, ShouldBeTrue)
+		So(key, ShouldEqual, "MULTI")
+		So(delimiter, ShouldStartWith, "ghadelimiter_")
+		So(lines[4:8], ShouldEqual, []string{"line one", "line two", "", delimiter})
+		crlfKey, crlfDelimiter, _ := strings.Cut(lines[8], "<<")
+		So(crlfKey, ShouldEqual, "CRLF")
+		So(lines[9:], ShouldEqual, []string{"a\r", "b\r", crlfDelimiter, ""})
+
+		Convey("round trips through a file", func() {
+			tempDir, err := os.MkdirTemp("", "corelibs-env.*.d")
+			So(err, ShouldBeNil)
+			defer os.RemoveAll(tempDir)
+			fh, err := os.OpenFile(tempDir+"/github_env", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			So(err, ShouldBeNil)
+			So(env.WriteGitHubEnv(fh), ShouldBeNil)
+			So(New().WriteGitHubEnv(fh), ShouldBeNil)
+			So(fh.Close(), ShouldBeNil)
+			fh, err = os.Open(tempDir + "/github_env")
+			So(err, ShouldBeNil)
+			defer fh.Close()
+			parsed, err := ParseGitHubEnv(fh)
+			So(err, ShouldBeNil)
+			So(parsed.Environ(), ShouldEqual, env.Environ())
+		})
+
+		Convey("avoids delimiter collisions", func() {
+			saved := gitHubDelimiter
+			defer func() { gitHubDelimiter = saved }()
+			var calls int
+			gitHubDelimiter = func() string {
+				calls += 1
+				if calls < 3 {
+					return "EOF"
+				}
+				return "EOF_" + saved()
+			}
+			colliding := New()
+			colliding.Set("SCRIPT", "cat <<EOF\nhello\nEOF")
+			var buf bytes.Buffer
+			So(colliding.WriteGitHubEnv(&buf), ShouldBeNil)
+			So(calls, ShouldEqual, 3)
+			So(buf.String(), ShouldStartWith, "SCRIPT<<EOF_ghadelimiter_")
+			parsed, err := ParseGitHubEnv(&buf)
+			So(err, ShouldBeNil)
+			So(parsed.String("SCRIPT", ""), ShouldEqual, "cat <<EOF\nhello\nEOF")
+		})
+
+		Convey("rejects invalid keys", func() {
+			for _, key := range []string{"A=B", "A<<B", "A\nB"} {
+				invalid := New()
+				invalid.Set("VALID", "value")
+				invalid.Set(key, "value")
+				var buf bytes.Buffer
+				err := invalid.WriteGitHubEnv(&buf)
+				So(err, ShouldWrap, ErrInvalidVariable)
+				So(buf.Len(), ShouldEqual, 0)
+			}
+		})
+	})
+
+	Convey("WriteGitHubPath", t, func() {
+		var buf bytes.Buffer
+		So(WriteGitHubPath(&buf, "/opt/sdk/bin", "/home/user/.local/bin"), ShouldBeNil)
+		So(buf.String(), ShouldEqual, "/opt/sdk/bin\n/home/user/.local/bin\n")
+		buf.Reset()
+		So(WriteGitHubPath(&buf, "/opt/bin", "/bad\npath"), ShouldWrap, ErrInvalidVariable)
+		So(WriteGitHubPath(&buf, ""), ShouldWrap, ErrInvalidVariable)
+		So(buf.Len(), ShouldEqual, 0)
+	})
+
+	Convey("ParseGitHubEnv", t, func() {
+		parsed, err := ParseGitHubEnv(strings.NewReader("" +
+			"ONE=1\r\n" +
+			"\r\n" +
+			"TWO<<END\r\n" +
+			"first\r\n" +
+			"END=not yet\r\n" +
+			"END\r\n" +
+			"EMPTY<<END\n" +
+			"END\n" +
+			"ONE=one\n"))
+		So(err, ShouldBeNil)
+		So(parsed.Environ(), ShouldEqual, []string{"ONE=one", "TWO=first\r\nEND=not yet\r", "EMPTY="})
+
+		for _, input := range []string{"NOPE\n", "=value\n", "<<END\nEND\n", "KEY<<\n"} {
+			_, err = ParseGitHubEnv(strings.NewReader(input))
+			So(err, ShouldWrap, ErrGitHubEnvFormat)
+		}
+		_, err = ParseGitHubEnv(strings.NewReader("KEY<<END\nvalue\n"))
+		So(err, ShouldWrap, ErrGitHubEnvDelimiter)
+		So(err.Error(), ShouldContainSubstring, "line 1")
+	})
+}
@@ -8,6 +8,8 @@ require (
 	github.com/go-corelibs/slices v1.2.0
 	github.com/go-corelibs/strings v1.1.1
 	github.com/smartystreets/goconvey v1.8.1
+	golang.org/x/net v0.17.0
+	golang.org/x/text v0.13.0
 )
 
 require (
@@ -27,7 +29,5 @@ require (
 	github.com/sirupsen/logrus v1.8.1 // indirect
 	github.com/smarty/assertions v1.15.0 // indirect
 	github.com/weppos/publicsuffix-go v0.30.1 // indirect
-	golang.org/x/net v0.17.0 // indirect
 	golang.org/x/sys v0.13.0 // indirect
-	golang.org/x/text v0.13.0 // indirect
 )
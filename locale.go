@@ -0,0 +1,147 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Locale category variable names
+const (
+	LcAll      = "LC_ALL"
+	LcCollate  = "LC_COLLATE"
+	LcCtype    = "LC_CTYPE"
+	LcMessages = "LC_MESSAGES"
+	LcMonetary = "LC_MONETARY"
+	LcNumeric  = "LC_NUMERIC"
+	LcTime     = "LC_TIME"
+)
+
+// Locale is a parsed locale name of the form:
+//
+//	language[_territory][.codeset][@modifier]
+type Locale struct {
+	// Category is the locale category, such as LC_MESSAGES
+	Category string
+	// Source is the variable the locale name came from, empty when the
+	// default "C" locale is used
+	Source string
+	// Name is the complete locale name, such as "en_US.UTF-8"
+	Name string
+	// Language is the language code, such as "en"
+	Language string
+	// Territory is the territory code, such as "US"
+	Territory string
+	// Codeset is the character set, such as "UTF-8"
+	Codeset string
+	// Modifier is the locale variant, such as "euro"
+	Modifier string
+	// Languages is the LANGUAGE priority list, which is only used for the
+	// LC_MESSAGES category and ignored for the "C" locale, as GNU gettext
+	// does
+	Languages []string
+}
+
+// ParseLocale splits the locale `name` into its component fields
+func ParseLocale(name string) (locale Locale) {
+	locale.Name = name
+	rest := name
+	rest, locale.Modifier, _ = strings.Cut(rest, "@")
+	rest, locale.Codeset, _ = strings.Cut(rest, ".")
+	locale.Language, locale.Territory, _ = strings.Cut(rest, "_")
+	return
+}
+
+func (c *cEnv) Locale(category string) (locale Locale) {
+	category = strings.ToUpper(category)
+	if !strings.HasPrefix(category, "LC_") {
+		category = "LC_" + category
+	}
+	var source, name string
+	for _, key := range []string{LcAll, category, "LANG"} {
+		if value := c.String(key, ""); value != "" {
+			source, name = key, value
+			break
+		}
+	}
+	if name == "" {
+		name = "C"
+	}
+	locale = ParseLocale(name)
+	locale.Category, locale.Source = category, source
+	if category == LcMessages && !locale.IsPOSIX() {
+		for _, entry := range strings.Split(c.String("LANGUAGE", ""), ":") {
+			if entry != "" {
+				locale.Languages = append(locale.Languages, entry)
+			}
+		}
+	}
+	return
+}
+
+// IsPOSIX reports whether this is the "C" or "POSIX" locale, with or
+// without a codeset
+func (l Locale) IsPOSIX() (posix bool) {
+	posix = l.Language == "C" || l.Language == "POSIX"
+	return
+}
+
+// IsUTF8 reports whether the Codeset is UTF-8, in any of its spellings
+func (l Locale) IsUTF8() (utf8 bool) {
+	codeset := strings.ReplaceAll(l.Codeset, "-", "")
+	utf8 = strings.EqualFold(codeset, "utf8")
+	return
+}
+
+// Tag returns the language.Tag for the Language and Territory, which is
+// language.Und for the "C" locale or any unrecognised language
+func (l Locale) Tag() (tag language.Tag) {
+	tag = language.Und
+	if l.IsPOSIX() || l.Language == "" {
+		return
+	}
+	value := l.Language
+	if l.Territory != "" {
+		value += "-" + l.Territory
+	}
+	if parsed, err := language.Parse(value); err == nil {
+		tag = parsed
+	}
+	return
+}
+
+// Tags returns the language.Tag for each of the Languages followed by the
+// Tag of this Locale, skipping undetermined and duplicate tags. The result
+// is suitable for use with a language.Matcher
+func (l Locale) Tags() (tags []language.Tag) {
+	add := func(tag language.Tag) {
+		if tag == language.Und {
+			return
+		}
+		for _, existing := range tags {
+			if existing == tag {
+				return
+			}
+		}
+		tags = append(tags, tag)
+	}
+	for _, entry := range l.Languages {
+		add(ParseLocale(entry).Tag())
+	}
+	add(l.Tag())
+	return
+}
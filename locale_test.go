@@ -0,0 +1,100 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"golang.org/x/text/language"
+)
+
+func TestLocale(t *testing.T) {
+	Convey("ParseLocale", t, func() {
+		So(ParseLocale("de_DE.ISO-8859-15@euro"), ShouldEqual, Locale{
+			Name:      "de_DE.ISO-8859-15@euro",
+			Language:  "de",
+			Territory: "DE",
+			Codeset:   "ISO-8859-15",
+			Modifier:  "euro",
+		})
+		So(ParseLocale("sr_RS@latin"), ShouldEqual, Locale{
+			Name:      "sr_RS@latin",
+			Language:  "sr",
+			Territory: "RS",
+			Modifier:  "latin",
+		})
+		So(ParseLocale("C.UTF-8"), ShouldEqual, Locale{
+			Name:     "C.UTF-8",
+			Language: "C",
+			Codeset:  "UTF-8",
+		})
+		So(ParseLocale("fr"), ShouldEqual, Locale{Name: "fr", Language: "fr"})
+	})
+
+	Convey("Env.Locale precedence", t, func() {
+		env := New()
+		locale := env.Locale(LcMessages)
+		So(locale.Name, ShouldEqual, "C")
+		So(locale.Source, ShouldEqual, "")
+		So(locale.IsPOSIX(), ShouldBeTrue)
+
+		env.Set("LANG", "en_US.UTF-8")
+		locale = env.Locale("messages")
+		So(locale.Category, ShouldEqual, LcMessages)
+		So(locale.Source, ShouldEqual, "LANG")
+		So(locale.Name, ShouldEqual, "en_US.UTF-8")
+
+		env.Set("LC_MESSAGES", "fr_CA.UTF-8")
+		env.Set("LC_TIME", "")
+		So(env.Locale(LcMessages).Source, ShouldEqual, LcMessages)
+		So(env.Locale(LcMessages).Name, ShouldEqual, "fr_CA.UTF-8")
+		So(env.Locale(LcTime).Source, ShouldEqual, "LANG")
+		So(env.Locale("lc_ctype").Name, ShouldEqual, "en_US.UTF-8")
+
+		env.Set("LC_ALL", "de_DE@euro")
+		So(env.Locale(LcMessages).Source, ShouldEqual, LcAll)
+		So(env.Locale(LcCtype).Name, ShouldEqual, "de_DE@euro")
+	})
+
+	Convey("Env.Locale LANGUAGE", t, func() {
+		env := New()
+		env.Set("LANGUAGE", "pt_BR:pt::en")
+		So(env.Locale(LcMessages).Languages, ShouldBeNil) // C locale ignores LANGUAGE
+		env.Set("LANG", "en_GB.utf8")
+		So(env.Locale(LcMessages).Languages, ShouldEqual, []string{"pt_BR", "pt", "en"})
+		So(env.Locale(LcCtype).Languages, ShouldBeNil)
+		So(env.Locale(LcMessages).Tags(), ShouldEqual, []language.Tag{
+			language.MustParse("pt-BR"),
+			language.Portuguese,
+			language.English,
+			language.BritishEnglish,
+		})
+	})
+
+	Convey("Locale helpers", t, func() {
+		So(ParseLocale("en_US.UTF-8").IsUTF8(), ShouldBeTrue)
+		So(ParseLocale("en_US.utf8").IsUTF8(), ShouldBeTrue)
+		So(ParseLocale("en_US.ISO-8859-1").IsUTF8(), ShouldBeFalse)
+		So(ParseLocale("POSIX").IsPOSIX(), ShouldBeTrue)
+		So(ParseLocale("POSIX").Tag(), ShouldEqual, language.Und)
+		So(ParseLocale("en_US.UTF-8").Tag(), ShouldEqual, language.AmericanEnglish)
+		So(ParseLocale("ja").Tag(), ShouldEqual, language.Japanese)
+		So(ParseLocale("!!").Tag(), ShouldEqual, language.Und)
+		So(ParseLocale("").Tag(), ShouldEqual, language.Und)
+		So(ParseLocale("C").Tags(), ShouldBeNil)
+	})
+}
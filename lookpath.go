@@ -0,0 +1,73 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-corelibs/slices"
+)
+
+func (c *cEnv) LookPath(name string) (path string, err error) {
+	if found := c.lookPath(name, true); len(found) > 0 {
+		path = found[0]
+		return
+	}
+	err = &exec.Error{Name: name, Err: exec.ErrNotFound}
+	return
+}
+
+func (c *cEnv) LookPathAll(name string) (paths []string) {
+	paths = c.lookPath(name, false)
+	return
+}
+
+func (c *cEnv) lookPath(name string, first bool) (paths []string) {
+	if name == "" {
+		return
+	}
+	if strings.ContainsRune(name, filepath.Separator) {
+		if isExecutable(name) {
+			paths = append(paths, name)
+		}
+		return
+	}
+	for _, dir := range NewPathList(c, "PATH", "").Paths() {
+		candidate := filepath.Join(dir, name)
+		if !strings.ContainsRune(candidate, filepath.Separator) {
+			// empty and "." entries join to the bare name, keep a separator
+			// so that exec does not search the process PATH
+			candidate = "." + string(filepath.Separator) + candidate
+		}
+		if isExecutable(candidate) && !slices.Within(candidate, paths) {
+			if paths = append(paths, candidate); first {
+				return
+			}
+		}
+	}
+	return
+}
+
+// isExecutable reports whether the path is a regular file with at least one
+// of the executable permission bits set
+func isExecutable(path string) (ok bool) {
+	if info, err := os.Stat(path); err == nil {
+		ok = info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0
+	}
+	return
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLookPath(t *testing.T) {
+	Convey("Env.LookPath and Env.LookPathAll", t, func() {
+		tempDir := t.TempDir()
+		for _, dir := range []string{"/a", "/b", "/c", "/b/tool.d"} {
+			So(os.MkdirAll(tempDir+dir, 0755), ShouldBeNil)
+		}
+		So(os.WriteFile(tempDir+"/a/tool", []byte("#!/bin/sh\n"), 0644), ShouldBeNil)
+		So(os.WriteFile(tempDir+"/b/tool", []byte("#!/bin/sh\n"), 0755), ShouldBeNil)
+		So(os.WriteFile(tempDir+"/c/tool", []byte("#!/bin/sh\n"), 0700), ShouldBeNil)
+		So(os.Symlink(tempDir+"/b/tool", tempDir+"/a/linked"), ShouldBeNil)
+		So(os.Mkdir(tempDir+"/c/linked", 0755), ShouldBeNil)
+
+		env := New()
+		env.Set("PATH", tempDir+"/a:"+tempDir+"/b:"+tempDir+"/b:"+tempDir+"/c")
+
+		path, err := env.LookPath("tool")
+		So(err, ShouldBeNil)
+		So(path, ShouldEqual, tempDir+"/b/tool")
+		So(env.LookPathAll("tool"), ShouldEqual, []string{tempDir + "/b/tool", tempDir + "/c/tool"})
+
+		path, err = env.LookPath("linked")
+		So(err, ShouldBeNil)
+		So(path, ShouldEqual, tempDir+"/a/linked")
+
+		path, err = env.LookPath(tempDir + "/c/tool")
+		So(err, ShouldBeNil)
+		So(path, ShouldEqual, tempDir+"/c/tool")
+		So(env.LookPathAll(tempDir+"/a/tool"), ShouldBeNil)
+
+		_, err = env.LookPath("nope")
+		So(errors.Is(err, exec.ErrNotFound), ShouldBeTrue)
+		_, err = env.LookPath("")
+		So(errors.Is(err, exec.ErrNotFound), ShouldBeTrue)
+		So(env.LookPathAll("tool.d"), ShouldBeNil)
+
+		pwd, _ := os.Getwd()
+		So(os.Chdir(tempDir+"/c"), ShouldBeNil)
+		defer func() { _ = os.Chdir(pwd) }()
+		env.Set("PATH", tempDir+"/a::"+tempDir+"/b")
+		So(env.LookPathAll("tool"), ShouldEqual, []string{"./tool", tempDir + "/b/tool"})
+
+		// relative entries keep a separator, even when joined to the bare name
+		env.Set("PATH", ".:"+tempDir+"/b")
+		path, err = env.LookPath("tool")
+		So(err, ShouldBeNil)
+		So(path, ShouldEqual, "./tool")
+		So(os.Chdir(tempDir), ShouldBeNil)
+		env.Set("PATH", "c:./b:a/../b")
+		So(env.LookPathAll("tool"), ShouldEqual, []string{"c/tool", "b/tool"})
+
+		_, err = New().LookPath("sh")
+		So(errors.Is(err, exec.ErrNotFound), ShouldBeTrue)
+	})
+}
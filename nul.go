@@ -0,0 +1,110 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	// MaxNULEntrySize is the largest "key=value" entry ImportNUL accepts,
+	// the default is the Linux MAX_ARG_STRLEN
+	MaxNULEntrySize = 128 * 1024
+	// MaxNULSize is the largest total input ImportNUL accepts
+	MaxNULSize = 16 * 1024 * 1024
+)
+
+var (
+	ErrEntryTooLarge   = errors.New("environment entry exceeds MaxNULEntrySize")
+	ErrEnvironTooLarge = errors.New("environment exceeds MaxNULSize")
+	ErrInvalidVariable = errors.New("invalid environment variable")
+)
+
+func (c *cEnv) ImportNUL(r io.Reader) (err error) {
+	var entries []string
+	br := bufio.NewReader(r)
+	var entry []byte
+	var total int
+	for {
+		chunk, ee := br.ReadSlice(0)
+		entry = append(entry, chunk...)
+		if total += len(chunk); total > MaxNULSize {
+			err = ErrEnvironTooLarge
+			return
+		} else if size := len(entry) - len(chunk) + len(bytes.TrimSuffix(chunk, []byte{0})); size > MaxNULEntrySize {
+			err = fmt.Errorf("%w: %d bytes", ErrEntryTooLarge, size)
+			return
+		}
+		switch {
+		case ee == nil:
+			entries = append(entries, string(entry[:len(entry)-1]))
+			entry = entry[:0]
+		case errors.Is(ee, bufio.ErrBufferFull):
+		case errors.Is(ee, io.EOF):
+			if len(entry) > 0 {
+				entries = append(entries, string(entry))
+			}
+			c.setEntries(entries)
+			return
+		default:
+			err = ee
+			return
+		}
+	}
+}
+
+// setEntries is Import without the quote trimming
+func (c *cEnv) setEntries(entries []string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	for _, entry := range entries {
+		if key, value, found := strings.Cut(entry, "="); found && key != "" {
+			if _, present := c.data[key]; !present {
+				c.order = append(c.order, key)
+			}
+			c.data[key] = value
+		}
+	}
+	c.resolved.flush()
+}
+
+func (c *cEnv) WriteNUL(w io.Writer) (err error) {
+	c.m.RLock()
+	variables := make([]string, 0, len(c.order))
+	for _, key := range c.order {
+		value := c.data[key]
+		if strings.ContainsAny(key, "=\x00") || strings.ContainsRune(value, 0) {
+			c.m.RUnlock()
+			err = fmt.Errorf("%w: %q", ErrInvalidVariable, key)
+			return
+		}
+		variables = append(variables, key+"="+value)
+	}
+	c.m.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, variable := range variables {
+		if _, err = bw.WriteString(variable + "\x00"); err != nil {
+			return
+		}
+	}
+	err = bw.Flush()
+	return
+}
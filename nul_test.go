@@ -0,0 +1,105 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNUL(t *testing.T) {
+	Convey("Env.ImportNUL", t, func() {
+		env := New()
+		env.Set("existing", "value")
+		So(env.ImportNUL(strings.NewReader("a=1\x00multi=line one\nline two\x00quoted='kept'\x00=ignored\x00novalue\x00existing=updated\x00last=unterminated")), ShouldBeNil)
+		So(env.Environ(), ShouldEqual, []string{
+			"existing=updated",
+			"a=1",
+			"multi=line one\nline two",
+			"quoted='kept'",
+			"last=unterminated",
+		})
+
+		env = New()
+		So(env.ImportNUL(iotest.OneByteReader(strings.NewReader("a=1\x00b=2\x00"))), ShouldBeNil)
+		So(env.Environ(), ShouldEqual, []string{"a=1", "b=2"})
+
+		env = New()
+		So(env.ImportNUL(strings.NewReader("")), ShouldBeNil)
+		So(env.Len(), ShouldEqual, 0)
+
+		// entries larger than the bufio buffer
+		long := strings.Repeat("x", 10000)
+		So(env.ImportNUL(strings.NewReader("long="+long+"\x00")), ShouldBeNil)
+		So(env.String("long", ""), ShouldEqual, long)
+
+		So(env.ImportNUL(iotest.ErrReader(io.ErrUnexpectedEOF)), ShouldEqual, io.ErrUnexpectedEOF)
+
+		savedEntry, savedTotal := MaxNULEntrySize, MaxNULSize
+		MaxNULEntrySize = 8
+		env = New()
+		So(env.ImportNUL(strings.NewReader("a=123456\x00")), ShouldBeNil)
+		err := env.ImportNUL(strings.NewReader("a=1234567\x00"))
+		So(errors.Is(err, ErrEntryTooLarge), ShouldBeTrue)
+		err = env.ImportNUL(strings.NewReader("a=1234567"))
+		So(errors.Is(err, ErrEntryTooLarge), ShouldBeTrue)
+		MaxNULEntrySize = savedEntry
+		MaxNULSize = 12
+		env = New()
+		err = env.ImportNUL(strings.NewReader("a=1\x00b=2\x00c=3\x00d=4\x00"))
+		So(errors.Is(err, ErrEnvironTooLarge), ShouldBeTrue)
+		So(env.Len(), ShouldEqual, 0)
+		MaxNULSize = savedTotal
+	})
+
+	Convey("Env.WriteNUL", t, func() {
+		env := New()
+		env.Set("a", "1")
+		env.Set("multi", "line one\nline two")
+		env.Set("empty", "")
+		var buf bytes.Buffer
+		So(env.WriteNUL(&buf), ShouldBeNil)
+		So(buf.String(), ShouldEqual, "a=1\x00multi=line one\nline two\x00empty=\x00")
+
+		other := New()
+		So(other.ImportNUL(&buf), ShouldBeNil)
+		So(other.Environ(), ShouldEqual, env.Environ())
+
+		buf.Reset()
+		env.Set("bad", "nul\x00value")
+		So(errors.Is(env.WriteNUL(&buf), ErrInvalidVariable), ShouldBeTrue)
+		So(buf.Len(), ShouldEqual, 0)
+		env.Unset("bad")
+		env.Set("bad=key", "value")
+		So(errors.Is(env.WriteNUL(&buf), ErrInvalidVariable), ShouldBeTrue)
+		env.Unset("bad=key")
+
+		So(env.WriteNUL(iotest.TruncateWriter(&buf, 0)), ShouldBeNil)
+		So(env.WriteNUL(errWriter{}), ShouldNotBeNil)
+	})
+}
+
+type errWriter struct{}
+
+func (errWriter) Write(p []byte) (n int, err error) {
+	err = io.ErrClosedPipe
+	return
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"os"
+	"strings"
+
+	"github.com/go-corelibs/slices"
+)
+
+var _ PathList = (*cPathList)(nil)
+
+// PathList is a list of paths stored in one Env variable, joined with a
+// separator, like PATH, MANPATH or XDG_DATA_DIRS. All changes are made to
+// the Env variable only, the os environment is never modified. Whitespace
+// around each path is removed, as the PATH helpers do, and empty entries are
+// preserved
+type PathList interface {
+	// Key returns the Env variable name
+	Key() (key string)
+	// Separator returns the string the paths are joined with
+	Separator() (separator string)
+	// String returns the Env variable value
+	String() (value string)
+	// Paths returns the separate paths, or nil if the variable is not set
+	// or is empty
+	Paths() (paths []string)
+	// Set replaces all paths with the ones given. Without any paths the
+	// variable is unset, as an empty value is the current directory to POSIX
+	// path searches
+	Set(paths ...string)
+	// Contains reports whether the `path` is present
+	Contains(path string) (present bool)
+	// Append moves or adds the `paths` to the end of the list, in the order
+	// given
+	Append(paths ...string)
+	// Prepend moves or adds the `paths` to the start of the list, in the
+	// order given
+	Prepend(paths ...string)
+	// Remove deletes all occurrences of the `paths`
+	Remove(paths ...string)
+	// InsertBefore moves or adds the `paths` before the first occurrence of
+	// the `mark` path. Nothing is changed if the `mark` is not `found`
+	InsertBefore(mark string, paths ...string) (found bool)
+	// InsertAfter moves or adds the `paths` after the first occurrence of
+	// the `mark` path. Nothing is changed if the `mark` is not `found`
+	InsertAfter(mark string, paths ...string) (found bool)
+	// Dedupe removes all but the first occurrence of each path
+	Dedupe()
+}
+
+type cPathList struct {
+	env       Env
+	key       string
+	separator string
+}
+
+// NewPathList constructs a new PathList instance for the `key` variable of
+// the given Env, with paths joined by the `separator`. A nil Env uses the
+// Default Env and an empty `separator` uses the os.PathListSeparator
+func NewPathList(e Env, key, separator string) (list PathList) {
+	if e == nil {
+		e = Default()
+	}
+	if separator == "" {
+		separator = string(os.PathListSeparator)
+	}
+	list = &cPathList{
+		env:       e,
+		key:       key,
+		separator: separator,
+	}
+	return
+}
+
+func (l *cPathList) Key() (key string) {
+	key = l.key
+	return
+}
+
+func (l *cPathList) Separator() (separator string) {
+	separator = l.separator
+	return
+}
+
+func (l *cPathList) String() (value string) {
+	value, _ = l.env.Get(l.key)
+	return
+}
+
+func (l *cPathList) Paths() (paths []string) {
+	paths = splitPaths(l.String(), l.separator)
+	return
+}
+
+func (l *cPathList) Set(paths ...string) {
+	paths = trimPaths(paths)
+	if len(paths) == 0 {
+		l.env.Unset(l.key)
+		return
+	}
+	l.env.Set(l.key, strings.Join(paths, l.separator))
+}
+
+func (l *cPathList) Contains(path string) (present bool) {
+	present = slices.Within(strings.TrimSpace(path), l.Paths())
+	return
+}
+
+func (l *cPathList) Append(paths ...string) {
+	paths = trimPaths(paths)
+	l.Set(append(slices.Prune(l.Paths(), paths...), paths...)...)
+}
+
+func (l *cPathList) Prepend(paths ...string) {
+	paths = trimPaths(paths)
+	l.Set(append(slices.Copy(paths), slices.Prune(l.Paths(), paths...)...)...)
+}
+
+func (l *cPathList) Remove(paths ...string) {
+	paths = trimPaths(paths)
+	l.Set(slices.Prune(l.Paths(), paths...)...)
+}
+
+func (l *cPathList) InsertBefore(mark string, paths ...string) (found bool) {
+	found = l.insert(mark, 0, paths)
+	return
+}
+
+func (l *cPathList) InsertAfter(mark string, paths ...string) (found bool) {
+	found = l.insert(mark, 1, paths)
+	return
+}
+
+func (l *cPathList) insert(mark string, offset int, paths []string) (found bool) {
+	mark, paths = strings.TrimSpace(mark), trimPaths(paths)
+	if slices.Within(mark, paths) {
+		// moving the mark relative to itself is meaningless
+		return
+	}
+	remaining := slices.Prune(l.Paths(), paths...)
+	idx := slices.IndexOf(remaining, mark)
+	if found = idx >= 0; !found {
+		return
+	}
+	l.Set(slices.Insert(remaining, idx+offset, paths...)...)
+	return
+}
+
+func (l *cPathList) Dedupe() {
+	l.Set(slices.Unique(l.Paths())...)
+}
+
+// splitPaths returns the paths of the list `value`, with the whitespace
+// around each removed, or nil when the `value` is empty
+func splitPaths(value, separator string) (paths []string) {
+	if value != "" {
+		paths = trimPaths(strings.Split(value, separator))
+	}
+	return
+}
+
+// trimPaths returns a copy of the paths with the whitespace around each
+// removed
+func trimPaths(paths []string) (trimmed []string) {
+	trimmed = make([]string, len(paths))
+	for idx, path := range paths {
+		trimmed[idx] = strings.TrimSpace(path)
+	}
+	return
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPathList(t *testing.T) {
+	Convey("NewPathList", t, func() {
+		list := NewPathList(nil, "PATH", "")
+		So(list.Key(), ShouldEqual, "PATH")
+		So(list.Separator(), ShouldEqual, string(os.PathListSeparator))
+		So(list.(*cPathList).env, ShouldEqual, Default())
+	})
+
+	Convey("PathList operations", t, func() {
+		env := New()
+		list := NewPathList(env, "LD_LIBRARY_PATH", ":")
+		So(list.Paths(), ShouldBeNil)
+		So(list.String(), ShouldEqual, "")
+		So(list.Contains("/lib"), ShouldBeFalse)
+
+		list.Append("/lib", "/usr/lib")
+		So(env.String("LD_LIBRARY_PATH", ""), ShouldEqual, "/lib:/usr/lib")
+		So(list.Contains("/lib"), ShouldBeTrue)
+
+		list.Prepend("/opt/lib", "/usr/lib")
+		So(list.Paths(), ShouldEqual, []string{"/opt/lib", "/usr/lib", "/lib"})
+
+		list.Append("/opt/lib")
+		So(list.Paths(), ShouldEqual, []string{"/usr/lib", "/lib", "/opt/lib"})
+
+		So(list.InsertBefore("/lib", "/a", "/b"), ShouldBeTrue)
+		So(list.Paths(), ShouldEqual, []string{"/usr/lib", "/a", "/b", "/lib", "/opt/lib"})
+
+		So(list.InsertAfter("/opt/lib", "/a"), ShouldBeTrue)
+		So(list.Paths(), ShouldEqual, []string{"/usr/lib", "/b", "/lib", "/opt/lib", "/a"})
+
+		So(list.InsertAfter("/nope", "/c"), ShouldBeFalse)
+		So(list.InsertBefore("/lib", "/lib"), ShouldBeFalse)
+		So(list.Paths(), ShouldEqual, []string{"/usr/lib", "/b", "/lib", "/opt/lib", "/a"})
+
+		list.Remove("/a", "/b", "/nope")
+		So(list.Paths(), ShouldEqual, []string{"/usr/lib", "/lib", "/opt/lib"})
+
+		list.Set("/a", "", "/b", "/a", "", "/c")
+		So(list.String(), ShouldEqual, "/a::/b:/a::/c")
+		list.Dedupe()
+		So(list.Paths(), ShouldEqual, []string{"/a", "", "/b", "/c"})
+
+		list.Remove("/a", "/b", "/c", "")
+		So(list.Paths(), ShouldBeNil)
+		_, present := env.Get("LD_LIBRARY_PATH")
+		So(present, ShouldBeFalse)
+
+		// removing the last path unsets the variable instead of leaving an
+		// empty value, which means the current directory
+		env.Set("PATH", "/a")
+		path := NewPathList(env, "PATH", ":")
+		path.Remove("/a")
+		_, present = env.Get("PATH")
+		So(present, ShouldBeFalse)
+		path.Dedupe()
+		_, present = env.Get("PATH")
+		So(present, ShouldBeFalse)
+		path.Set("")
+		value, present := env.Get("PATH")
+		So(present, ShouldBeTrue)
+		So(value, ShouldEqual, "")
+	})
+
+	Convey("PathList separators", t, func() {
+		env := New()
+		env.Set("MY_LIST", "one,two")
+		list := NewPathList(env, "MY_LIST", ",")
+		list.Append("three")
+		So(env.String("MY_LIST", ""), ShouldEqual, "one,two,three")
+	})
+
+	Convey("PathList whitespace", t, func() {
+		env := New()
+		env.Set("PATH", " /a : /b ")
+		list := NewPathList(env, "PATH", ":")
+		So(list.Paths(), ShouldEqual, []string{"/a", "/b"})
+		So(list.Contains(" /b "), ShouldBeTrue)
+		list.Append(" /a ")
+		So(list.String(), ShouldEqual, "/b:/a")
+		So(list.InsertBefore(" /a", "/c "), ShouldBeTrue)
+		So(list.String(), ShouldEqual, "/b:/c:/a")
+		list.Remove(" /b")
+		So(list.String(), ShouldEqual, "/c:/a")
+	})
+}
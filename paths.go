@@ -17,6 +17,7 @@ package env
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	clpath "github.com/go-corelibs/path"
@@ -29,69 +30,281 @@ func PATH() (path string) {
 	return
 }
 
-// PATHS returns a slice of paths from the current PATH environment
+// PATHS returns a slice of paths from the current PATH environment, see
+// NewPathList for other Env instances and list variables
 func PATHS() (paths []string) {
-	if value := String("PATH", ""); value != "" {
-		paths = strings.Split(value, ":")
-		for i := 0; i < len(paths); i++ {
-			paths[i] = strings.TrimSpace(paths[i])
+	paths = NewPathList(nil, "PATH", ":").Paths()
+	return
+}
+
+// osPATHS is PATHS for the os environment
+func osPATHS() (paths []string) {
+	paths = splitPaths(os.Getenv("PATH"), ":")
+	return
+}
+
+// updatePATH applies the `update` to the PATH of each target separately,
+// reading the Default Env PATH for TargetEnv and the os environment PATH
+// for TargetOS
+func updatePATH(target Target, update func(paths []string) (updated []string)) (err error) {
+	if target&TargetEnv != 0 {
+		err = SetPATH(TargetEnv, update(PATHS()))
+	}
+	if err == nil && target&TargetOS != 0 {
+		err = SetPATH(TargetOS, update(osPATHS()))
+	}
+	return
+}
+
+// Target selects where the ...To PATH helpers apply their changes. Each
+// target is updated from its own PATH, so the Default Env PATH is never
+// copied into the os environment or the other way around. PrunePATH,
+// AppendPATH, PrependPATH and TidyPATH instead compute the result from the
+// Default Env PATH and set both to it
+type Target uint8
+
+const (
+	// TargetEnv applies changes to the Default Env
+	TargetEnv Target = 1 << iota
+	// TargetOS applies changes to the os environment
+	TargetOS
+	// TargetBoth applies changes to the Default Env and the os environment
+	TargetBoth = TargetEnv | TargetOS
+)
+
+// TidyOptions configures TidyPaths
+type TidyOptions struct {
+	// Env is used to expand variables and the tilde, the Default Env is used
+	// when nil
+	Env Env
+	// DropEmpty removes empty entries, which POSIX treats as the current
+	// directory and are kept by default
+	DropEmpty bool
+	// ExpandVars replaces $VAR and ${VAR} references with the Env values,
+	// using os.Expand
+	ExpandVars bool
+	// ExpandTilde replaces a leading "~" with the HOME directory
+	ExpandTilde bool
+	// DropRelative removes entries which are not absolute paths
+	DropRelative bool
+	// ResolveSymlinks replaces entries with their absolute, symlink-free,
+	// real paths
+	ResolveSymlinks bool
+	// Dedupe removes entries resolving to the same real path as an earlier
+	// entry
+	Dedupe bool
+	// DropWorldWritable removes directories any user can write to
+	DropWorldWritable bool
+}
+
+// RemovedPath describes an entry removed by TidyPaths
+type RemovedPath struct {
+	Path   string
+	Reason string
+}
+
+// SetPATH joins the paths and updates the PATH variable of the target. As
+// with PathList.Set, the PATH is unset when there are no paths
+func SetPATH(target Target, paths []string) (err error) {
+	paths = trimPaths(paths)
+	if target&TargetEnv != 0 {
+		NewPathList(nil, "PATH", ":").Set(paths...)
+	}
+	if target&TargetOS != 0 {
+		if len(paths) == 0 {
+			err = os.Unsetenv("PATH")
+		} else {
+			err = os.Setenv("PATH", strings.Join(paths, ":"))
 		}
 	}
 	return
 }
 
-// PrunePATH removes the given path from the PATH environment variable
-func PrunePATH(path string) (err error) {
+// PrunePaths returns a copy of the paths without the given path. Like
+// PATHS, whitespace around the paths is removed
+func PrunePaths(paths []string, path string) (pruned []string) {
+	pruned = slices.Prune(trimPaths(paths), strings.TrimSpace(path))
+	return
+}
+
+// AppendPaths returns a copy of the paths with the given path moved or
+// added to the end
+func AppendPaths(paths []string, path string) (appended []string) {
 	path = strings.TrimSpace(path)
-	paths := PATHS()
-	paths = slices.Prune(paths, path)
-	joined := strings.Join(paths, ":")
-	Set("PATH", joined)
-	err = os.Setenv("PATH", joined)
+	appended = append(PrunePaths(paths, path), path)
+	return
+}
+
+// PrependPaths returns a copy of the paths with the given path moved or
+// added to the start
+func PrependPaths(paths []string, path string) (prepended []string) {
+	path = strings.TrimSpace(path)
+	prepended = append([]string{path}, PrunePaths(paths, path)...)
+	return
+}
+
+// TidyPaths returns the cleaned paths which actually exist as directories
+// on the filesystem, along with the entries removed and why. Empty entries
+// are kept unless the options say otherwise
+func TidyPaths(paths []string, options TidyOptions) (tidy []string, removed []RemovedPath) {
+	e := options.Env
+	if e == nil {
+		e = Default()
+	}
+	seen := make(map[string]string)
+	for _, original := range paths {
+		path, reason := tidyPath(e, original, options)
+		if reason == "" && options.Dedupe {
+			real := path
+			if real == "" {
+				real = "."
+			}
+			if abs, ee := filepath.Abs(real); ee == nil {
+				real = abs
+			}
+			if resolved, ee := filepath.EvalSymlinks(real); ee == nil {
+				real = resolved
+			}
+			if first, found := seen[real]; found {
+				reason = "duplicate of " + strconv.Quote(first)
+			} else {
+				seen[real] = original
+			}
+		}
+		if reason != "" {
+			removed = append(removed, RemovedPath{Path: original, Reason: reason})
+			continue
+		}
+		tidy = append(tidy, path)
+	}
+	return
+}
+
+// tidyPath returns the tidied path or the reason it is to be removed
+func tidyPath(e Env, path string, options TidyOptions) (tidied, reason string) {
+	if path == "" {
+		if options.DropEmpty {
+			reason = "empty"
+		}
+		return
+	}
+	if options.ExpandVars {
+		path = os.Expand(path, func(key string) (value string) {
+			value, _ = e.Get(key)
+			return
+		})
+		if path == "" {
+			reason = "empty after expansion"
+			return
+		}
+	}
+	if options.ExpandTilde && (path == "~" || strings.HasPrefix(path, "~/")) {
+		if home, _ := e.Get("HOME"); home != "" {
+			path = home + path[1:]
+		}
+	}
+	if options.DropRelative && !filepath.IsAbs(path) {
+		reason = "relative"
+		return
+	}
+	if !clpath.IsDir(path) {
+		reason = "not a directory"
+		return
+	}
+	if options.ResolveSymlinks {
+		var err error
+		if path, err = filepath.Abs(path); err == nil {
+			path, err = filepath.EvalSymlinks(path)
+		}
+		if err != nil {
+			reason = "unresolvable: " + err.Error()
+			return
+		}
+	}
+	tidied = filepath.Clean(path)
+	if options.DropWorldWritable {
+		if info, err := os.Stat(tidied); err == nil && info.Mode().Perm()&0002 != 0 {
+			tidied, reason = "", "world-writable"
+		}
+	}
+	return
+}
+
+// PrunePATH removes the given path from the PATH environment variable,
+// setting both the Default Env and the os environment PATH to the result
+// computed from the Default Env PATH
+func PrunePATH(path string) (err error) {
+	err = SetPATH(TargetBoth, PrunePaths(PATHS(), path))
+	return
+}
+
+// PrunePATHTo removes the given path from the PATH environment variable of
+// the target, see Target
+func PrunePATHTo(target Target, path string) (err error) {
+	err = updatePATH(target, func(paths []string) []string {
+		return PrunePaths(paths, path)
+	})
 	return
 }
 
 // AppendPATH moves or adds the given path to the end of the PATH
-// environment variable
+// environment variable, setting both the Default Env and the os environment
+// PATH to the result computed from the Default Env PATH
 func AppendPATH(path string) (err error) {
-	path = strings.TrimSpace(path)
-	paths := PATHS()
-	paths = slices.Prune(paths, path)
-	paths = append(paths, path)
-	joined := strings.Join(paths, ":")
-	Set("PATH", joined)
-	err = os.Setenv("PATH", joined)
+	err = SetPATH(TargetBoth, AppendPaths(PATHS(), path))
+	return
+}
+
+// AppendPATHTo moves or adds the given path to the end of the PATH
+// environment variable of the target, see Target
+func AppendPATHTo(target Target, path string) (err error) {
+	err = updatePATH(target, func(paths []string) []string {
+		return AppendPaths(paths, path)
+	})
 	return
 }
 
 // PrependPATH moves or adds the given path to the start of the PATH
-// environment variable
+// environment variable, setting both the Default Env and the os environment
+// PATH to the result computed from the Default Env PATH
 func PrependPATH(path string) (err error) {
-	path = strings.TrimSpace(path)
-	paths := PATHS()
-	paths = slices.Prune(paths, path)
-	paths = append([]string{path}, paths...)
-	joined := strings.Join(paths, ":")
-	Set("PATH", joined)
-	err = os.Setenv("PATH", joined)
+	err = SetPATH(TargetBoth, PrependPaths(PATHS(), path))
+	return
+}
+
+// PrependPATHTo moves or adds the given path to the start of the PATH
+// environment variable of the target, see Target
+func PrependPATHTo(target Target, path string) (err error) {
+	err = updatePATH(target, func(paths []string) []string {
+		return PrependPaths(paths, path)
+	})
 	return
 }
 
 // TidyPATH removes all paths from the PATH environment variable that
 // do not actually exist on the filesystem or cannot be resolved to
-// their absolute, cleaned, paths
+// their absolute, cleaned, paths, setting both the Default Env and the os
+// environment PATH to the result computed from the Default Env PATH. Empty
+// entries are kept
 func TidyPATH() (err error) {
-	paths := PATHS()
-	var existing []string
-	for _, path := range paths {
-		if clpath.IsDir(path) {
-			if _, ee := filepath.Abs(path); ee == nil {
-				existing = append(existing, filepath.Clean(path))
+	tidy, _ := TidyPaths(PATHS(), TidyOptions{})
+	err = SetPATH(TargetBoth, tidy)
+	return
+}
+
+// TidyPATHTo is TidyPATH with options, updating only the target and
+// returning the entries removed. With TargetBoth, each PATH is tidied
+// separately, see Target, and the entries removed from the Default Env are
+// followed by any others removed from the os environment
+func TidyPATHTo(target Target, options TidyOptions) (removed []RemovedPath, err error) {
+	err = updatePATH(target, func(paths []string) []string {
+		tidy, dropped := TidyPaths(paths, options)
+		for _, entry := range dropped {
+			if !slices.Within(entry, removed) {
+				removed = append(removed, entry)
 			}
 		}
-	}
-	joined := strings.Join(existing, ":")
-	Set("PATH", joined)
-	err = os.Setenv("PATH", joined)
+		return tidy
+	})
 	return
 }
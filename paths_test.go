@@ -15,6 +15,7 @@
 package env
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"slices"
@@ -91,6 +92,8 @@ func TestPaths(t *testing.T) {
 	Convey("TidyPath", t, func() {
 		m.Lock()
 		defer m.Unlock()
+		saved := os.Getenv("PATH")
+		defer func() { _ = os.Setenv("PATH", saved) }()
 		So(os.Setenv("PATH", "/usr/bin:/usr/local/bin"), ShouldBeNil)
 		_env = NewImport(os.Environ())
 		actual := os.Getenv("PATH")
@@ -106,4 +109,158 @@ func TestPaths(t *testing.T) {
 		So(PATHS(), ShouldEqual, append(actuals, pwd))
 		So(PrunePATH(pwd), ShouldBeNil)
 	})
+
+	Convey("Pure path helpers", t, func() {
+		paths := []string{"/a", "/b", "/c"}
+		So(PrunePaths(paths, " /b "), ShouldEqual, []string{"/a", "/c"})
+		So(AppendPaths(paths, "/a"), ShouldEqual, []string{"/b", "/c", "/a"})
+		So(PrependPaths(paths, "/c"), ShouldEqual, []string{"/c", "/a", "/b"})
+		So(paths, ShouldEqual, []string{"/a", "/b", "/c"})
+		So(PrependPaths([]string{" /a "}, "/b"), ShouldEqual, []string{"/b", "/a"})
+		So(AppendPaths([]string{" /a ", "/b"}, " /a"), ShouldEqual, []string{"/b", "/a"})
+
+		pwd := path.Pwd()
+		messy := []string{pwd + "/.", "", "/not/a/real/path", "/usr/bin"}
+		tidy, removed := TidyPaths(messy, TidyOptions{})
+		So(tidy, ShouldEqual, []string{pwd, "", "/usr/bin"})
+		So(removed, ShouldEqual, []RemovedPath{{Path: "/not/a/real/path", Reason: "not a directory"}})
+		tidy, _ = TidyPaths(messy, TidyOptions{DropEmpty: true})
+		So(tidy, ShouldEqual, []string{pwd, "/usr/bin"})
+	})
+
+	Convey("TidyPaths options", t, func() {
+		tempDir, err := filepath.EvalSymlinks(t.TempDir())
+		So(err, ShouldBeNil)
+		for _, dir := range []string{"/home/bin", "/real", "/open"} {
+			So(os.MkdirAll(tempDir+dir, 0755), ShouldBeNil)
+		}
+		So(os.Chmod(tempDir+"/open", 0777), ShouldBeNil)
+		So(os.Symlink(tempDir+"/real", tempDir+"/link"), ShouldBeNil)
+
+		env := New()
+		env.Set("HOME", tempDir+"/home")
+		env.Set("BASE", tempDir)
+		paths := []string{
+			"~/bin",
+			"$BASE/real",
+			"${BASE}/link",
+			tempDir + "/link/",
+			"$NOPE",
+			"",
+			"relative",
+			tempDir + "/open",
+			tempDir + "/real",
+		}
+
+		tidy, removed := TidyPaths(paths, TidyOptions{Env: env})
+		So(tidy, ShouldEqual, []string{tempDir + "/link", "", tempDir + "/open", tempDir + "/real"})
+		So(len(removed), ShouldEqual, 5)
+
+		tidy, removed = TidyPaths(paths, TidyOptions{
+			Env:               env,
+			DropEmpty:         true,
+			ExpandVars:        true,
+			ExpandTilde:       true,
+			DropRelative:      true,
+			ResolveSymlinks:   true,
+			Dedupe:            true,
+			DropWorldWritable: true,
+		})
+		So(tidy, ShouldEqual, []string{tempDir + "/home/bin", tempDir + "/real"})
+		So(removed, ShouldEqual, []RemovedPath{
+			{Path: "${BASE}/link", Reason: `duplicate of "$BASE/real"`},
+			{Path: tempDir + "/link/", Reason: `duplicate of "$BASE/real"`},
+			{Path: "$NOPE", Reason: "empty after expansion"},
+			{Path: "", Reason: "empty"},
+			{Path: "relative", Reason: "relative"},
+			{Path: tempDir + "/open", Reason: "world-writable"},
+			{Path: tempDir + "/real", Reason: `duplicate of "$BASE/real"`},
+		})
+
+		// relative entries are only made absolute when resolving symlinks
+		real, err := filepath.EvalSymlinks(path.Pwd())
+		So(err, ShouldBeNil)
+		tidy, _ = TidyPaths([]string{"."}, TidyOptions{})
+		So(tidy, ShouldEqual, []string{"."})
+		tidy, _ = TidyPaths([]string{"."}, TidyOptions{ResolveSymlinks: true})
+		So(tidy, ShouldEqual, []string{real})
+
+		tidy, removed = TidyPaths([]string{tempDir + "/link", tempDir + "/real", "", ""}, TidyOptions{Dedupe: true})
+		So(tidy, ShouldEqual, []string{tempDir + "/link", ""})
+		So(removed, ShouldEqual, []RemovedPath{
+			{Path: tempDir + "/real", Reason: fmt.Sprintf("duplicate of %q", tempDir+"/link")},
+			{Path: "", Reason: `duplicate of ""`},
+		})
+	})
+
+	Convey("PATH targets", t, func() {
+		m.Lock()
+		defer m.Unlock()
+		saved := os.Getenv("PATH")
+		defer func() { _ = os.Setenv("PATH", saved) }()
+		So(os.Setenv("PATH", "/usr/bin:/usr/local/bin"), ShouldBeNil)
+		_env = NewImport(os.Environ())
+
+		So(AppendPATHTo(TargetEnv, "/opt/bin"), ShouldBeNil)
+		So(PATH(), ShouldEqual, "/usr/bin:/usr/local/bin:/opt/bin")
+		So(os.Getenv("PATH"), ShouldEqual, "/usr/bin:/usr/local/bin")
+
+		So(PrependPATHTo(TargetOS, "/opt/bin"), ShouldBeNil)
+		So(PATH(), ShouldEqual, "/usr/bin:/usr/local/bin:/opt/bin")
+		So(os.Getenv("PATH"), ShouldEqual, "/opt/bin:/usr/bin:/usr/local/bin")
+
+		So(PrunePATHTo(TargetBoth, "/opt/bin"), ShouldBeNil)
+		So(PATH(), ShouldEqual, "/usr/bin:/usr/local/bin")
+		So(os.Getenv("PATH"), ShouldEqual, "/usr/bin:/usr/local/bin")
+
+		So(SetPATH(TargetBoth, []string{"/usr/bin", "", "/nope"}), ShouldBeNil)
+		So(TidyPATH(), ShouldBeNil)
+		So(PATH(), ShouldEqual, "/usr/bin:")
+		removed, err := TidyPATHTo(TargetEnv, TidyOptions{DropEmpty: true})
+		So(err, ShouldBeNil)
+		So(removed, ShouldEqual, []RemovedPath{{Path: "", Reason: "empty"}})
+		So(PATH(), ShouldEqual, "/usr/bin")
+		So(os.Getenv("PATH"), ShouldEqual, "/usr/bin:")
+		So(SetPATH(TargetBoth, []string{"/usr/bin", "/usr/local/bin"}), ShouldBeNil)
+
+		// changes to one target never leak into the other
+		So(AppendPATHTo(TargetEnv, "/env-only"), ShouldBeNil)
+		So(AppendPATHTo(TargetOS, "/os-only"), ShouldBeNil)
+		So(PATH(), ShouldEqual, "/usr/bin:/usr/local/bin:/env-only")
+		So(os.Getenv("PATH"), ShouldEqual, "/usr/bin:/usr/local/bin:/os-only")
+		So(AppendPATHTo(TargetBoth, "/usr/bin"), ShouldBeNil)
+		So(PATH(), ShouldEqual, "/usr/local/bin:/env-only:/usr/bin")
+		So(os.Getenv("PATH"), ShouldEqual, "/usr/local/bin:/os-only:/usr/bin")
+		So(PrunePATHTo(TargetBoth, "/usr/local/bin"), ShouldBeNil)
+		So(PATH(), ShouldEqual, "/env-only:/usr/bin")
+		So(os.Getenv("PATH"), ShouldEqual, "/os-only:/usr/bin")
+		removed, err = TidyPATHTo(TargetBoth, TidyOptions{})
+		So(err, ShouldBeNil)
+		So(removed, ShouldEqual, []RemovedPath{{Path: "/env-only", Reason: "not a directory"}, {Path: "/os-only", Reason: "not a directory"}})
+		So(PATH(), ShouldEqual, "/usr/bin")
+		So(os.Getenv("PATH"), ShouldEqual, "/usr/bin")
+
+		// the legacy helpers copy the Default Env result to the os PATH
+		So(SetPATH(TargetEnv, []string{"/env-only", "/usr/bin"}), ShouldBeNil)
+		So(AppendPATH("/opt/bin"), ShouldBeNil)
+		So(PATH(), ShouldEqual, "/env-only:/usr/bin:/opt/bin")
+		So(os.Getenv("PATH"), ShouldEqual, "/env-only:/usr/bin:/opt/bin")
+		So(SetPATH(TargetOS, []string{"/os-only"}), ShouldBeNil)
+		So(PrunePATH("/opt/bin"), ShouldBeNil)
+		So(os.Getenv("PATH"), ShouldEqual, "/env-only:/usr/bin")
+		So(SetPATH(TargetOS, []string{"/os-only"}), ShouldBeNil)
+		So(PrependPATH("/usr/bin"), ShouldBeNil)
+		So(os.Getenv("PATH"), ShouldEqual, "/usr/bin:/env-only")
+		So(SetPATH(TargetOS, []string{"/os-only"}), ShouldBeNil)
+		So(TidyPATH(), ShouldBeNil)
+		So(PATH(), ShouldEqual, "/usr/bin")
+		So(os.Getenv("PATH"), ShouldEqual, "/usr/bin")
+
+		// an empty PATH is the current directory, so no paths unsets it
+		So(SetPATH(TargetBoth, nil), ShouldBeNil)
+		_, present := Get("PATH")
+		So(present, ShouldBeFalse)
+		_, present = os.LookupEnv("PATH")
+		So(present, ShouldBeFalse)
+	})
 }
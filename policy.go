@@ -0,0 +1,115 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+var ErrUnsafeValue = errors.New(`value contains "%" or "/"`)
+
+// Policy describes the variables Env.Sanitize keeps, similar to the sudo
+// env_keep and env_check options. Each list entry is either an exact key or
+// a path.Match pattern
+type Policy struct {
+	// Keep lists the variables kept as-is
+	Keep []string
+	// Check lists the variables kept only when Validate accepts their value
+	Check []string
+	// Deny lists the variables always dropped, regardless of Keep or Check
+	Deny []string
+	// Validate checks the values of the Check variables, when nil the
+	// DefaultValidate function is used
+	Validate func(key, value string) (err error)
+}
+
+// Dropped describes a variable removed by Env.Sanitize
+type Dropped struct {
+	Key    string
+	Reason string
+}
+
+// DefaultValidate rejects values containing either a percent sign or a
+// forward slash, the same as the sudo env_check option
+func DefaultValidate(key, value string) (err error) {
+	if strings.ContainsAny(value, "%/") {
+		err = ErrUnsafeValue
+	}
+	return
+}
+
+func (p Policy) denies(key string) (denied bool) {
+	denied = matchAny(key, p.Deny)
+	return
+}
+
+func (p Policy) keeps(key string) (kept bool) {
+	kept = matchAny(key, p.Keep)
+	return
+}
+
+func (p Policy) checks(key string) (checked bool) {
+	checked = matchAny(key, p.Check)
+	return
+}
+
+func (p Policy) validate(key, value string) (err error) {
+	if p.Validate != nil {
+		err = p.Validate(key, value)
+		return
+	}
+	err = DefaultValidate(key, value)
+	return
+}
+
+func (c *cEnv) Sanitize(policy Policy) (clean Env, dropped []Dropped) {
+	clean = c.Clone()
+	for _, variable := range clean.Environ() {
+		key, value, _ := strings.Cut(variable, "=")
+		var reason string
+		switch {
+		case policy.denies(key):
+			reason = "denied"
+		case policy.keeps(key):
+		case policy.checks(key):
+			if err := policy.validate(key, value); err != nil {
+				reason = "check failed: " + err.Error()
+			}
+		default:
+			reason = "not kept"
+		}
+		if reason != "" {
+			clean.Unset(key)
+			dropped = append(dropped, Dropped{Key: key, Reason: reason})
+		}
+	}
+	return
+}
+
+// matchAny reports whether the key is equal to or matches any of the
+// path.Match patterns given, invalid patterns never match
+func matchAny(key string, patterns []string) (matched bool) {
+	for _, pattern := range patterns {
+		if pattern == key {
+			matched = true
+			return
+		} else if matched, _ = path.Match(pattern, key); matched {
+			return
+		}
+	}
+	return
+}
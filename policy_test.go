@@ -0,0 +1,78 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPolicy(t *testing.T) {
+	Convey("Env.Sanitize", t, func() {
+		env := New()
+		env.Set("PATH", "/usr/bin:/bin")
+		env.Set("HOME", "/home/someone")
+		env.Set("LANG", "en_US.UTF-8")
+		env.Set("LC_ALL", "C")
+		env.Set("TZ", "/etc/localtime")
+		env.Set("LD_PRELOAD", "/tmp/evil.so")
+		env.Set("LD_LIBRARY_PATH", "/tmp")
+		env.Set("DB_PASSWORD", "hunter2")
+		env.Set("TERM", "xterm%s")
+
+		policy := Policy{
+			Keep:  []string{"PATH", "HOME", "LD_*"},
+			Check: []string{"LANG", "LC_*", "TERM", "TZ"},
+			Deny:  []string{"LD_PRELOAD"},
+		}
+		clean, dropped := env.Sanitize(policy)
+		So(clean.Environ(), ShouldEqual, []string{
+			"PATH=/usr/bin:/bin",
+			"HOME=/home/someone",
+			"LANG=en_US.UTF-8",
+			"LC_ALL=C",
+			"LD_LIBRARY_PATH=/tmp",
+		})
+		So(dropped, ShouldEqual, []Dropped{
+			{Key: "TZ", Reason: `check failed: value contains "%" or "/"`},
+			{Key: "LD_PRELOAD", Reason: "denied"},
+			{Key: "DB_PASSWORD", Reason: "not kept"},
+			{Key: "TERM", Reason: `check failed: value contains "%" or "/"`},
+		})
+		So(env.Len(), ShouldEqual, 9)
+		So(clean.IsSecret("DB_PASSWORD"), ShouldBeTrue)
+
+		policy.Validate = func(key, value string) (err error) {
+			if strings.Contains(value, "%") {
+				err = errors.New("format verb")
+			}
+			return
+		}
+		clean, dropped = env.Sanitize(policy)
+		So(clean.String("TZ", ""), ShouldEqual, "/etc/localtime")
+		So(dropped, ShouldEqual, []Dropped{
+			{Key: "LD_PRELOAD", Reason: "denied"},
+			{Key: "DB_PASSWORD", Reason: "not kept"},
+			{Key: "TERM", Reason: "check failed: format verb"},
+		})
+
+		clean, dropped = env.Sanitize(Policy{Keep: []string{"[bad"}})
+		So(clean.Len(), ShouldEqual, 0)
+		So(len(dropped), ShouldEqual, 9)
+	})
+}
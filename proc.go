@@ -0,0 +1,56 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+)
+
+// ProcPath is the mount point of the proc filesystem used by FromPID
+var ProcPath = "/proc"
+
+var ErrInvalidPID = errors.New("invalid pid")
+
+// FromPID reads the environment a running process was started with from
+// /proc/<pid>/environ. Values are used exactly as found, without any quote
+// trimming. Note that changes a process makes to its own environment after
+// starting are not reflected there
+func FromPID(pid int) (env Env, err error) {
+	if pid <= 0 {
+		err = fmt.Errorf("%w: %d", ErrInvalidPID, pid)
+		return
+	}
+	filename := ProcPath + "/" + strconv.Itoa(pid) + "/environ"
+	var fh *os.File
+	if fh, err = os.Open(filename); err != nil {
+		switch {
+		case errors.Is(err, fs.ErrPermission):
+			err = fmt.Errorf("reading the environment of pid %d requires the same user or CAP_SYS_PTRACE: %w", pid, err)
+		case errors.Is(err, fs.ErrNotExist):
+			err = fmt.Errorf("pid %d not found: %w", pid, err)
+		}
+		return
+	}
+	defer fh.Close()
+	env = New()
+	if err = env.ImportNUL(fh); err != nil {
+		env = nil
+	}
+	return
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProc(t *testing.T) {
+	Convey("FromPID", t, func() {
+		if _, err := os.Stat("/proc/self/environ"); err != nil {
+			SkipSo("/proc is not available")
+			return
+		}
+
+		cmd := exec.Command("sleep", "5")
+		cmd.Env = []string{
+			"QUOTED='value'",
+			"MULTI=line one\nline two",
+			"EMPTY=",
+		}
+		So(cmd.Start(), ShouldBeNil)
+		defer func() {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		}()
+
+		env, err := FromPID(cmd.Process.Pid)
+		So(err, ShouldBeNil)
+		So(env.Environ(), ShouldEqual, cmd.Env)
+
+		_, err = FromPID(0)
+		So(errors.Is(err, ErrInvalidPID), ShouldBeTrue)
+
+		saved := ProcPath
+		ProcPath = t.TempDir()
+		_, err = FromPID(cmd.Process.Pid)
+		ProcPath = saved
+		So(errors.Is(err, fs.ErrNotExist), ShouldBeTrue)
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+func (c *cEnv) ProxyFunc() (proxy func(req *http.Request) (*url.URL, error)) {
+	config := &httpproxy.Config{
+		HTTPProxy:  c.anyOf("HTTP_PROXY", "http_proxy"),
+		HTTPSProxy: c.anyOf("HTTPS_PROXY", "https_proxy"),
+		NoProxy:    c.anyOf("NO_PROXY", "no_proxy"),
+		CGI:        c.String("REQUEST_METHOD", "") != "",
+	}
+	fn := config.ProxyFunc()
+	proxy = func(req *http.Request) (*url.URL, error) {
+		return fn(req.URL)
+	}
+	return
+}
+
+// anyOf returns the first non-empty value of the given keys
+func (c *cEnv) anyOf(keys ...string) (value string) {
+	for _, key := range keys {
+		if value, _ = c.Get(key); value != "" {
+			return
+		}
+	}
+	return
+}
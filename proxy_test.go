@@ -0,0 +1,65 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProxy(t *testing.T) {
+	Convey("Env.ProxyFunc", t, func() {
+		proxyFor := func(e Env, target string) string {
+			req, err := http.NewRequest(http.MethodGet, target, nil)
+			So(err, ShouldBeNil)
+			found, err := e.ProxyFunc()(req)
+			So(err, ShouldBeNil)
+			if found == nil {
+				return ""
+			}
+			return found.String()
+		}
+
+		env := New()
+		So(proxyFor(env, "http://example.com"), ShouldEqual, "")
+
+		env.Set("HTTP_PROXY", "http://plain.proxy:3128")
+		env.Set("https_proxy", "secure.proxy:3129")
+		env.Set("no_proxy", "10.0.0.0/8,.internal,example.org:8080")
+		So(proxyFor(env, "http://example.com"), ShouldEqual, "http://plain.proxy:3128")
+		So(proxyFor(env, "https://example.com"), ShouldEqual, "http://secure.proxy:3129")
+		So(proxyFor(env, "http://10.1.2.3"), ShouldEqual, "")
+		So(proxyFor(env, "http://api.internal"), ShouldEqual, "")
+		So(proxyFor(env, "http://example.org:8080"), ShouldEqual, "")
+		So(proxyFor(env, "http://example.org"), ShouldEqual, "http://plain.proxy:3128")
+		So(proxyFor(env, "http://localhost"), ShouldEqual, "")
+
+		env.Set("HTTPS_PROXY", "http://upper.proxy:3129")
+		env.Set("http_proxy", "http://lower.proxy:3128")
+		So(proxyFor(env, "https://example.com"), ShouldEqual, "http://upper.proxy:3129")
+		So(proxyFor(env, "http://example.com"), ShouldEqual, "http://plain.proxy:3128")
+
+		env.Set("HTTP_PROXY", "")
+		So(proxyFor(env, "http://example.com"), ShouldEqual, "http://lower.proxy:3128")
+
+		env.Set("REQUEST_METHOD", "GET")
+		req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		_, err := env.ProxyFunc()(req)
+		So(err, ShouldNotBeNil)
+		So(proxyFor(env, "https://example.com"), ShouldEqual, "http://upper.proxy:3129")
+	})
+}
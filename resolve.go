@@ -0,0 +1,206 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Resolver is a function which transforms the `ref` part of a
+// "scheme:ref" value into the actual value. The Env given is the one
+// performing the lookup, calls to its Get, Lookup, Bool, Int, Float and
+// String methods count towards the MaxResolveDepth
+type Resolver func(e Env, ref string) (value string, err error)
+
+// MaxResolveDepth is the number of nested lookups a Resolver may perform
+// before giving up with ErrResolveDepth
+var MaxResolveDepth = 8
+
+var (
+	ErrResolveDepth = errors.New("too many nested resolver lookups")
+	ErrNotPresent   = errors.New("variable not present")
+)
+
+// FileResolver reads the named file, for use with values like
+// "file:///run/secrets/db". The same MaxIndirectFileSize limit and trailing
+// newline trimming as ResolveFiles applies
+func FileResolver(_ Env, ref string) (value string, err error) {
+	value, err = readIndirectFile(strings.TrimPrefix(ref, "//"))
+	return
+}
+
+// Base64Resolver decodes standard, padded or unpadded, base64 encoded
+// values, for use with values like "base64:aHVudGVyMg=="
+func Base64Resolver(_ Env, ref string) (value string, err error) {
+	var data []byte
+	if data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(ref, "=")); err == nil {
+		value = string(data)
+	}
+	return
+}
+
+// EnvResolver returns the value of another variable within the same Env,
+// for use with values like "env:OTHER_KEY"
+func EnvResolver(e Env, ref string) (value string, err error) {
+	var present bool
+	if value, present, err = e.Lookup(ref); err == nil && !present {
+		err = fmt.Errorf("%w: %s", ErrNotPresent, ref)
+	}
+	return
+}
+
+func (c *cEnv) RegisterResolver(scheme string, resolver Resolver) {
+	if scheme == "" {
+		return
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	if resolver == nil {
+		delete(c.schemes, scheme)
+	} else {
+		c.schemes[scheme] = resolver
+	}
+	c.resolved.flush()
+}
+
+// resolve is called by lookup, without holding any locks
+func (c *cEnv) resolve(key, value string, depth int) (resolved string, err error) {
+	resolved = value
+	scheme, ref, found := strings.Cut(value, ":")
+	if !found {
+		return
+	}
+
+	c.m.RLock()
+	resolver, registered := c.schemes[scheme]
+	c.m.RUnlock()
+	if !registered {
+		return
+	}
+
+	cached, generation, ok := c.resolved.get(value)
+	if ok {
+		resolved = cached
+		return
+	}
+
+	if depth >= MaxResolveDepth {
+		err = fmt.Errorf("%s: %w", key, ErrResolveDepth)
+		return
+	}
+
+	resolving := &cResolving{cEnv: c, depth: depth + 1}
+	if resolved, err = resolver(resolving, ref); err == nil && resolving.err != nil {
+		// the depth was exceeded by a lookup which does not report errors
+		err = resolving.err
+	}
+	if err != nil {
+		err = fmt.Errorf("%s: %s resolver: %w", key, scheme, err)
+		return
+	}
+	c.resolved.set(generation, value, resolved)
+	return
+}
+
+// cResolving is the Env given to a Resolver, tracking the lookup depth and
+// the first ErrResolveDepth encountered
+type cResolving struct {
+	*cEnv
+	depth int
+	err   error
+}
+
+func (r *cResolving) Get(key string) (value string, present bool) {
+	var err error
+	if value, present, err = r.Lookup(key); err != nil {
+		value, present = "", false
+	}
+	return
+}
+
+func (r *cResolving) Lookup(key string) (value string, present bool, err error) {
+	value, present, err = r.cEnv.lookup(key, r.depth)
+	if r.err == nil && errors.Is(err, ErrResolveDepth) {
+		r.err = err
+	}
+	return
+}
+
+func (r *cResolving) Bool(key string, def bool) (state bool) {
+	value, present := r.Get(key)
+	state = toBool(value, present, def)
+	return
+}
+
+func (r *cResolving) Int(key string, def int) (number int) {
+	value, present := r.Get(key)
+	number = toInt(value, present, def)
+	return
+}
+
+func (r *cResolving) Float(key string, def float64) (decimal float64) {
+	value, present := r.Get(key)
+	decimal = toFloat(value, present, def)
+	return
+}
+
+func (r *cResolving) String(key string, def string) (value string) {
+	v, present := r.Get(key)
+	value = toString(v, present, def)
+	return
+}
+
+type cResolved struct {
+	values     map[string]string
+	generation uint64
+	m          *sync.RWMutex
+}
+
+func newResolved() (resolved *cResolved) {
+	resolved = &cResolved{
+		values: make(map[string]string),
+		m:      &sync.RWMutex{},
+	}
+	return
+}
+
+func (r *cResolved) get(raw string) (value string, generation uint64, ok bool) {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	value, ok = r.values[raw]
+	generation = r.generation
+	return
+}
+
+// set caches the value unless the cache was flushed since the given
+// generation was returned by get
+func (r *cResolved) set(generation uint64, raw, value string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if generation == r.generation {
+		r.values[raw] = value
+	}
+}
+
+func (r *cResolved) flush() {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.values = make(map[string]string)
+	r.generation += 1
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestResolve(t *testing.T) {
+	Convey("Builtin resolvers", t, func() {
+		tempDir, err := os.MkdirTemp("", "corelibs-env.*.d")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(tempDir)
+		So(os.WriteFile(tempDir+"/db", []byte("hunter2\n"), 0600), ShouldBeNil)
+
+		env := New()
+		env.Set("FROM_FILE", "file://"+tempDir+"/db")
+		env.Set("FROM_BASE64", "base64:aHVudGVyMg==")
+		env.Set("FROM_RAW_BASE64", "base64:aHVudGVyMg")
+		env.Set("FROM_ENV", "env:FROM_BASE64")
+		env.Set("URL", "https://example.com")
+
+		// nothing is resolved until registered
+		So(env.String("FROM_BASE64", ""), ShouldEqual, "base64:aHVudGVyMg==")
+
+		env.RegisterResolver("file", FileResolver)
+		env.RegisterResolver("base64", Base64Resolver)
+		env.RegisterResolver("env", EnvResolver)
+		env.RegisterResolver("", EnvResolver)
+		So(env.String("FROM_FILE", ""), ShouldEqual, "hunter2")
+		So(env.String("FROM_BASE64", ""), ShouldEqual, "hunter2")
+		So(env.String("FROM_RAW_BASE64", ""), ShouldEqual, "hunter2")
+		So(env.String("FROM_ENV", ""), ShouldEqual, "hunter2")
+		So(env.String("URL", ""), ShouldEqual, "https://example.com")
+
+		env.Set("MISSING", "env:NOT_A_THING")
+		_, present, err := env.Lookup("MISSING")
+		So(present, ShouldBeFalse)
+		So(errors.Is(err, ErrNotPresent), ShouldBeTrue)
+		So(err.Error(), ShouldEqual, "MISSING: env resolver: variable not present: NOT_A_THING")
+
+		env.Set("LOOP", "env:LOOP")
+		_, _, err = env.Lookup("LOOP")
+		So(errors.Is(err, ErrResolveDepth), ShouldBeTrue)
+
+		env.RegisterResolver("ref", func(e Env, ref string) (string, error) {
+			return e.String(ref, ""), nil
+		})
+		env.RegisterResolver("num", func(e Env, ref string) (string, error) {
+			return fmt.Sprint(e.Int(ref, 0) + 1), nil
+		})
+		env.Set("TYPED_LOOP", "ref:TYPED_LOOP")
+		_, _, err = env.Lookup("TYPED_LOOP")
+		So(errors.Is(err, ErrResolveDepth), ShouldBeTrue)
+		So(env.String("TYPED_LOOP", "default"), ShouldEqual, "default")
+		env.Set("NUM_LOOP", "num:NUM_LOOP")
+		_, _, err = env.Lookup("NUM_LOOP")
+		So(errors.Is(err, ErrResolveDepth), ShouldBeTrue)
+		env.Set("TYPED_REF", "ref:URL")
+		So(env.String("TYPED_REF", ""), ShouldEqual, "https://example.com")
+
+		env.RegisterResolver("base64", nil)
+		So(env.String("FROM_BASE64", ""), ShouldEqual, "base64:aHVudGVyMg==")
+	})
+
+	Convey("Custom resolver caching", t, func() {
+		calls := 0
+		store := map[string]string{"db/password": "hunter2"}
+		env := New()
+		env.RegisterResolver("vault", func(e Env, ref string) (value string, err error) {
+			calls += 1
+			var ok bool
+			if value, ok = store[ref]; !ok {
+				err = errors.New("secret not found")
+			}
+			return
+		})
+		env.Set("DB_PASSWORD", "vault:db/password")
+		env.Set("OTHER", "vault:nope")
+		So(env.String("DB_PASSWORD", ""), ShouldEqual, "hunter2")
+		So(env.String("DB_PASSWORD", ""), ShouldEqual, "hunter2")
+		So(calls, ShouldEqual, 1)
+		So(env.String("OTHER", "default"), ShouldEqual, "default")
+		So(env.String("OTHER", "default"), ShouldEqual, "default")
+		So(calls, ShouldEqual, 3) // errors are not cached
+		env.Set("UNRELATED", "change")
+		So(env.String("DB_PASSWORD", ""), ShouldEqual, "hunter2")
+		So(calls, ShouldEqual, 4)
+		cloned := env.Clone()
+		So(cloned.String("DB_PASSWORD", ""), ShouldEqual, "hunter2")
+		So(calls, ShouldEqual, 5)
+	})
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+)
+
+// RedactedValue is the replacement text used for secret values
+const RedactedValue = "***"
+
+// DefaultSecretPatterns are the path.Match patterns every new Env instance
+// uses to detect sensitive keys
+var DefaultSecretPatterns = []string{
+	"*_TOKEN",
+	"*_PASSWORD",
+	"*_KEY",
+}
+
+func (c *cEnv) MarkSecret(keys ...string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	for _, key := range keys {
+		if key != "" {
+			c.secrets[key] = struct{}{}
+		}
+	}
+}
+
+func (c *cEnv) MarkSecretPattern(patterns ...string) (err error) {
+	for _, pattern := range patterns {
+		if _, err = path.Match(pattern, ""); err != nil {
+			err = fmt.Errorf("%w: %q", err, pattern)
+			return
+		}
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.patterns = append(c.patterns, patterns...)
+	return
+}
+
+func (c *cEnv) IsSecret(key string) (secret bool) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	secret = c.isSecret(key)
+	return
+}
+
+func (c *cEnv) isSecret(key string) (secret bool) {
+	if _, secret = c.secrets[key]; secret {
+		return
+	}
+	for _, pattern := range c.patterns {
+		if secret, _ = path.Match(pattern, key); secret {
+			return
+		}
+	}
+	return
+}
+
+// redact returns the RedactedValue if the key is secret, must be called with
+// at least a read lock held
+func (c *cEnv) redact(key, value string) (redacted string) {
+	if c.isSecret(key) {
+		redacted = RedactedValue
+		return
+	}
+	redacted = value
+	return
+}
+
+func (c *cEnv) Redacted() (variables []string) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	for _, key := range c.order {
+		if value, present := c.data[key]; present {
+			variables = append(variables, key+"="+c.redact(key, value))
+		}
+	}
+	return
+}
+
+func (c *cEnv) Format(f fmt.State, verb rune) {
+	_, _ = fmt.Fprintf(f, fmt.FormatString(f, verb), c.Redacted())
+}
+
+func (c *cEnv) LogValue() (value slog.Value) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	var attrs []slog.Attr
+	for _, key := range c.order {
+		if v, present := c.data[key]; present {
+			attrs = append(attrs, slog.String(key, c.redact(key, v)))
+		}
+	}
+	value = slog.GroupValue(attrs...)
+	return
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultShell is the shell used by Env.Source when none is given
+	DefaultShell = "sh"
+	// DefaultLoginShell is the shell used by Env.LoginShell when the SHELL
+	// variable is not set
+	DefaultLoginShell = "/bin/sh"
+)
+
+// LoginShellTimeout is the time limit Env.LoginShell applies when the
+// context given has no deadline
+var LoginShellTimeout = 10 * time.Second
+
+// captureMarker separates any unexpected shell output from the env -0
+// output captured
+const captureMarker = "__CORELIBS_ENV_CAPTURE__"
+
+// captureScript prints the captureMarker and the environment, it is
+// appended to the shell commands run by the capture function
+const captureScript = `printf '%s\000' ` + captureMarker + ` && exec env -0`
+
+var ErrCaptureMarker = errors.New("environment capture marker not found")
+
+// sourceShellKeys are variables the shell itself sets, which are ignored
+// unless already present before sourcing
+var sourceShellKeys = []string{"_", "SHLVL", "PWD", "OLDPWD"}
+
+func (c *cEnv) Source(ctx context.Context, script, shell string) (sourced Env, err error) {
+	if shell == "" {
+		shell = DefaultShell
+	}
+	if !strings.Contains(script, "/") {
+		// the dot command searches PATH for names without a slash
+		script = "./" + script
+	}
+	// the script output is sent to stderr, keeping stdout for the capture
+	command := `. "$1" >&2 && ` + captureScript
+	if sourced, err = c.capture(ctx, shell, "-c", command, shell, script); err != nil {
+		return
+	}
+	c.pruneShellKeys(sourced)
+	return
+}
+
+func (c *cEnv) LoginShell(ctx context.Context) (login Env, err error) {
+	shell := c.String("SHELL", "")
+	if shell == "" {
+		shell = DefaultLoginShell
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, LoginShellTimeout)
+		defer cancel()
+	}
+	if login, err = c.capture(ctx, shell, "-l", "-c", captureScript); err != nil {
+		err = fmt.Errorf("%s login shell: %w", shell, err)
+		return
+	}
+	c.pruneShellKeys(login)
+	return
+}
+
+// pruneShellKeys removes the sourceShellKeys from the captured Env which are
+// not present in this Env, checking the variables directly so that neither
+// access tracking, _FILE indirection nor the resolvers are involved
+func (c *cEnv) pruneShellKeys(captured Env) {
+	var missing []string
+	c.m.RLock()
+	for _, key := range sourceShellKeys {
+		if _, present := c.data[key]; !present {
+			missing = append(missing, key)
+		}
+	}
+	c.m.RUnlock()
+	for _, key := range missing {
+		captured.Unset(key)
+	}
+}
+
+// capture runs the `name` command within this Env and returns a clone of
+// this Env with the variables updated to those printed after the
+// captureMarker
+func (c *cEnv) capture(ctx context.Context, name string, args ...string) (captured Env, err error) {
+	var stdout, stderr bytes.Buffer
+	cmd := c.Command(ctx, name, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err = cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			err = fmt.Errorf("%w: %s", err, message)
+		}
+		return
+	}
+	output := stdout.Bytes()
+	idx := bytes.Index(output, []byte(captureMarker+"\x00"))
+	if idx < 0 {
+		err = ErrCaptureMarker
+		return
+	}
+	// existing variables keep their order, new ones are appended in the
+	// order captured
+	found := New()
+	if err = found.ImportNUL(bytes.NewReader(output[idx+len(captureMarker)+1:])); err != nil {
+		return
+	}
+	captured = c.Clone()
+	for _, variable := range c.Environ() {
+		key, _, _ := strings.Cut(variable, "=")
+		if _, present := found.Get(key); !present {
+			captured.Unset(key)
+		}
+	}
+	for _, variable := range found.Environ() {
+		key, value, _ := strings.Cut(variable, "=")
+		captured.Set(key, value)
+	}
+	return
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSource(t *testing.T) {
+	Convey("Env.Source", t, func() {
+		tempDir, err := os.MkdirTemp("", "corelibs-env.*.d")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(tempDir)
+		So(os.WriteFile(tempDir+"/setup.sh", []byte(`
+echo "welcome to the vendor sdk"
+export SDK_HOME=/opt/sdk
+export SDK_BANNER="line one
+line two"
+export SDK_TOKEN=secret
+unset REMOVED
+CHANGED="$CHANGED:more"
+`), 0644), ShouldBeNil)
+		So(os.WriteFile(tempDir+"/broken.sh", []byte("echo oops >&2\nfalse\n"), 0644), ShouldBeNil)
+
+		env := New()
+		env.Set("PATH", os.Getenv("PATH"))
+		env.Set("REMOVED", "value")
+		env.Set("CHANGED", "value")
+
+		for _, shell := range []string{"", "bash"} {
+			sourced, err := env.Source(context.Background(), tempDir+"/setup.sh", shell)
+			So(err, ShouldBeNil)
+			So(sourced.Len(), ShouldEqual, 5)
+			So(sourced.Environ()[:2], ShouldEqual, []string{
+				"PATH=" + os.Getenv("PATH"),
+				"CHANGED=value:more",
+			})
+			So(sourced.String("SDK_HOME", ""), ShouldEqual, "/opt/sdk")
+			So(sourced.String("SDK_BANNER", ""), ShouldEqual, "line one\nline two")
+			So(sourced.String("SDK_TOKEN", ""), ShouldEqual, "secret")
+			changes := env.Diff(sourced)
+			So(len(changes), ShouldEqual, 5)
+			So(changes[:2], ShouldEqual, []Change{
+				{Op: Removed, Key: "REMOVED", Old: "value"},
+				{Op: Modified, Key: "CHANGED", Old: "value", New: "value:more"},
+			})
+			So(changes, ShouldContain, Change{Op: Added, Key: "SDK_TOKEN", New: "secret", Secret: true})
+		}
+
+		// checking for the shell variables is not a read of them
+		env.TrackAccess(true)
+		_, err = env.Source(context.Background(), tempDir+"/setup.sh", "")
+		So(err, ShouldBeNil)
+		So(env.AccessReport().Missing, ShouldBeEmpty)
+		env.TrackAccess(false)
+
+		// relative names are found in the current directory, not the PATH
+		pwd, _ := os.Getwd()
+		So(os.Chdir(tempDir), ShouldBeNil)
+		defer func() { _ = os.Chdir(pwd) }()
+		sourced, err := env.Source(context.Background(), "setup.sh", "")
+		So(err, ShouldBeNil)
+		So(sourced.String("SDK_HOME", ""), ShouldEqual, "/opt/sdk")
+
+		_, err = env.Source(context.Background(), tempDir+"/broken.sh", "")
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "exit status 1: oops")
+
+		_, err = env.Source(context.Background(), tempDir+"/nope.sh", "")
+		So(err, ShouldNotBeNil)
+
+		_, err = env.Source(context.Background(), tempDir+"/setup.sh", "not-a-shell")
+		So(err, ShouldNotBeNil)
+
+		_, err = env.(*cEnv).capture(context.Background(), "sh", "-c", "true")
+		So(err, ShouldEqual, ErrCaptureMarker)
+	})
+
+	Convey("Env.LoginShell", t, func() {
+		tempDir, err := os.MkdirTemp("", "corelibs-env.*.d")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(tempDir)
+		So(os.WriteFile(tempDir+"/loginsh", []byte(`#!/bin/sh
+echo "Last login: yesterday"
+printf 'motd\000with=nul\000'
+[ "$1" = "-l" ] && shift && export LOGIN_PROFILE=loaded
+export LOGIN_QUOTED='"%s"' LOGIN_SINGLE="'single'"
+exec sh "$@"
+`), 0755), ShouldBeNil)
+		So(os.WriteFile(tempDir+"/slowsh", []byte("#!/bin/sh\nexec sleep 5\n"), 0755), ShouldBeNil)
+
+		env := New()
+		env.Set("PATH", os.Getenv("PATH"))
+		env.Set("SHELL", tempDir+"/loginsh")
+		login, err := env.LoginShell(context.Background())
+		So(err, ShouldBeNil)
+		So(login.String("LOGIN_PROFILE", ""), ShouldEqual, "loaded")
+		So(login.String("SHELL", ""), ShouldEqual, tempDir+"/loginsh")
+		_, present := login.Get("with")
+		So(present, ShouldBeFalse)
+		_, present = login.Get("SHLVL")
+		So(present, ShouldBeFalse)
+
+		// login values are kept exactly when included
+		included := New()
+		included.Include(login)
+		So(included.String("LOGIN_QUOTED", ""), ShouldEqual, `"%s"`)
+		So(included.String("LOGIN_SINGLE", ""), ShouldEqual, "'single'")
+
+		login, err = env.LoginShell(nil)
+		So(err, ShouldBeNil)
+		So(login.String("LOGIN_PROFILE", ""), ShouldEqual, "loaded")
+
+		env.Set("SHELL", tempDir+"/slowsh")
+		saved := LoginShellTimeout
+		LoginShellTimeout = 100 * time.Millisecond
+		_, err = env.LoginShell(context.Background())
+		LoginShellTimeout = saved
+		So(err, ShouldNotBeNil)
+
+		env.Unset("SHELL")
+		login, err = env.LoginShell(context.Background())
+		So(err, ShouldBeNil)
+		So(login.String("PATH", ""), ShouldNotEqual, "")
+	})
+}
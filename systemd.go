@@ -0,0 +1,312 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrInvalidUTF8 = errors.New("invalid utf-8")
+
+const (
+	// systemdComments start a comment when found before a key
+	systemdComments = "#;"
+	// systemdWhitespace is skipped around keys and values
+	systemdWhitespace = " \t\n\r"
+	// systemdNewline ends an entry
+	systemdNewline = "\n\r"
+	// systemdNeedEscape are backslash escaped within double quotes
+	systemdNeedEscape = "\"\\`$"
+	// systemdNeedQuotes are the characters which WriteSystemdEnv quotes
+	// values for, along with whitespace and control characters
+	systemdNeedQuotes = systemdNeedEscape + "*?[" + "'()<>|&;!"
+)
+
+type systemdState uint8
+
+const (
+	systemdPreKey systemdState = iota
+	systemdKey
+	systemdPreValue
+	systemdValue
+	systemdValueEscape
+	systemdSingleQuote
+	systemdDoubleQuote
+	systemdDoubleQuoteEscape
+	systemdComment
+	systemdCommentEscape
+)
+
+// ParseSystemdEnv reads the EnvironmentFile= format of systemd, which is
+// also used by /etc/environment and os-release files. This is a port of the
+// parse_env_file_internal state machine in systemd's src/basic/env-file.c:
+//
+//   - lines starting with "#" or ";" are comments, and a comment ending in
+//     a backslash does not continue onto the next line
+//   - whitespace around keys and unquoted values is removed
+//   - a backslash outside of quotes escapes the next character, a
+//     backslash before a newline joins the lines
+//   - single quoted text is used as-is
+//   - within double quotes, a backslash escapes only the characters
+//     "\"\\`$" and a newline, before anything else the backslash is kept
+//   - quoted and unquoted text following each other are joined
+//   - lines without an equal sign are ignored
+//
+// Later assignments replace earlier ones, keeping the original position.
+// Keys which are not valid variable names are skipped, as systemd does
+// when starting services, and input which is not valid UTF-8 is an error.
+// Parsing stops at the first NUL byte. See WithEncryptionKey for decrypting
+// values as they are read
+func ParseSystemdEnv(r io.Reader, options ...FileOption) (env Env, err error) {
+	o := newFileOptions(options)
+	var contents []byte
+	if contents, err = io.ReadAll(r); err != nil {
+		return
+	}
+	if idx := bytes.IndexByte(contents, 0); idx >= 0 {
+		contents = contents[:idx]
+	}
+
+	parsed := newEnv()
+	var key, value []byte
+	lastKeySpace, lastValueSpace := -1, -1
+	line, start := 1, 1
+
+	push := func() (err error) {
+		if lastKeySpace >= 0 {
+			key = key[:lastKeySpace]
+		}
+		if !utf8.Valid(key) || !utf8.Valid(value) {
+			err = fmt.Errorf("%w: line %d", ErrInvalidUTF8, start)
+			return
+		}
+		if name := string(key); isVariableName(name) {
+			var loaded string
+			if loaded, err = o.load(parsed, name, string(value)); err != nil {
+				err = fmt.Errorf("line %d: %w", start, err)
+				return
+			}
+			parsed.Set(name, loaded)
+		}
+		key, value = key[:0], value[:0]
+		return
+	}
+
+	state := systemdPreKey
+	for _, c := range contents {
+		in := func(set string) bool { return strings.IndexByte(set, c) >= 0 }
+
+		switch state {
+		case systemdPreKey:
+			if in(systemdComments) {
+				state = systemdComment
+			} else if !in(systemdWhitespace) {
+				state = systemdKey
+				lastKeySpace = -1
+				start = line
+				key = append(key, c)
+			}
+
+		case systemdKey:
+			if in(systemdNewline) {
+				state = systemdPreKey
+				key = key[:0]
+			} else if c == '=' {
+				state = systemdPreValue
+				lastValueSpace = -1
+			} else {
+				if !in(systemdWhitespace) {
+					lastKeySpace = -1
+				} else if lastKeySpace < 0 {
+					lastKeySpace = len(key)
+				}
+				key = append(key, c)
+			}
+
+		case systemdPreValue:
+			if in(systemdNewline) {
+				state = systemdPreKey
+				if err = push(); err != nil {
+					return
+				}
+			} else if c == '\'' {
+				state = systemdSingleQuote
+			} else if c == '"' {
+				state = systemdDoubleQuote
+			} else if c == '\\' {
+				state = systemdValueEscape
+			} else if !in(systemdWhitespace) {
+				state = systemdValue
+				value = append(value, c)
+			}
+
+		case systemdValue:
+			if in(systemdNewline) {
+				state = systemdPreKey
+				if lastValueSpace >= 0 {
+					value = value[:lastValueSpace]
+				}
+				if err = push(); err != nil {
+					return
+				}
+			} else if c == '\\' {
+				state = systemdValueEscape
+				lastValueSpace = -1
+			} else {
+				if !in(systemdWhitespace) {
+					lastValueSpace = -1
+				} else if lastValueSpace < 0 {
+					lastValueSpace = len(value)
+				}
+				value = append(value, c)
+			}
+
+		case systemdValueEscape:
+			state = systemdValue
+			if !in(systemdNewline) {
+				// escaped newlines are removed entirely
+				value = append(value, c)
+			}
+
+		case systemdSingleQuote:
+			if c == '\'' {
+				state = systemdPreValue
+			} else {
+				value = append(value, c)
+			}
+
+		case systemdDoubleQuote:
+			if c == '"' {
+				state = systemdPreValue
+			} else if c == '\\' {
+				state = systemdDoubleQuoteEscape
+			} else {
+				value = append(value, c)
+			}
+
+		case systemdDoubleQuoteEscape:
+			state = systemdDoubleQuote
+			if in(systemdNeedEscape) {
+				value = append(value, c)
+			} else if c != '\n' {
+				// the backslash is kept, like the shell does
+				value = append(value, '\\', c)
+			}
+
+		case systemdComment:
+			if c == '\\' {
+				state = systemdCommentEscape
+			} else if in(systemdNewline) {
+				state = systemdPreKey
+			}
+
+		case systemdCommentEscape:
+			if in(systemdNewline) {
+				state = systemdPreKey
+			} else {
+				state = systemdComment
+			}
+		}
+
+		if c == '\n' {
+			line += 1
+		}
+	}
+
+	switch state {
+	case systemdValue:
+		if lastValueSpace >= 0 {
+			value = value[:lastValueSpace]
+		}
+		fallthrough
+	case systemdPreValue, systemdValueEscape, systemdSingleQuote, systemdDoubleQuote, systemdDoubleQuoteEscape:
+		if err = push(); err != nil {
+			return
+		}
+	}
+
+	env = parsed
+	return
+}
+
+func (c *cEnv) WriteSystemdEnv(w io.Writer, options ...FileOption) (err error) {
+	o := newFileOptions(options)
+	c.m.RLock()
+	var buf strings.Builder
+	for _, key := range c.order {
+		value := c.data[key]
+		if !isVariableName(key) || strings.ContainsRune(value, 0) {
+			c.m.RUnlock()
+			err = fmt.Errorf("%w: %q", ErrInvalidVariable, key)
+			return
+		}
+		if value, err = o.write(c.isSecret(key), key, value); err != nil {
+			c.m.RUnlock()
+			return
+		}
+		buf.WriteString(key + "=")
+		if needsSystemdQuotes(value) {
+			buf.WriteByte('"')
+			for idx := 0; idx < len(value); idx++ {
+				if strings.IndexByte(systemdNeedEscape, value[idx]) >= 0 {
+					buf.WriteByte('\\')
+				}
+				buf.WriteByte(value[idx])
+			}
+			buf.WriteByte('"')
+		} else {
+			buf.WriteString(value)
+		}
+		buf.WriteByte('\n')
+	}
+	c.m.RUnlock()
+
+	_, err = io.WriteString(w, buf.String())
+	return
+}
+
+// needsSystemdQuotes reports whether the value has any whitespace, control
+// or shell special characters
+func needsSystemdQuotes(value string) (needed bool) {
+	for _, r := range value {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(systemdWhitespace+systemdNeedQuotes, r) {
+			needed = true
+			return
+		}
+	}
+	return
+}
+
+// isVariableName reports whether the name is made of ASCII letters, digits
+// and underscores, not starting with a digit
+func isVariableName(name string) (valid bool) {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return
+	}
+	for idx := 0; idx < len(name); idx++ {
+		switch c := name[idx]; {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		default:
+			return
+		}
+	}
+	valid = true
+	return
+}
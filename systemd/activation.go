@@ -0,0 +1,143 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package systemd provides the systemd service protocols which are driven
+// by environment variables: socket activation, readiness and watchdog
+// notifications and service credentials
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-corelibs/env"
+)
+
+const (
+	// ListenPID is the variable naming the process socket activation is for
+	ListenPID = "LISTEN_PID"
+	// ListenFDs is the variable with the number of file descriptors passed
+	ListenFDs = "LISTEN_FDS"
+	// ListenFDNames is the variable with the colon separated descriptor names
+	ListenFDNames = "LISTEN_FDNAMES"
+)
+
+// UnknownName is the name of file descriptors passed without LISTEN_FDNAMES
+const UnknownName = "unknown"
+
+// ListenFDsStart is the first file descriptor passed by socket activation,
+// SD_LISTEN_FDS_START
+var ListenFDsStart = 3
+
+var (
+	ErrListenPID     = errors.New("invalid LISTEN_PID")
+	ErrListenFDs     = errors.New("invalid LISTEN_FDS")
+	ErrListenFDNames = errors.New("invalid LISTEN_FDNAMES")
+)
+
+// Files returns the file descriptors passed by socket activation, named
+// from LISTEN_FDNAMES or with the UnknownName. Nothing is returned when
+// LISTEN_PID or LISTEN_FDS are not set, or LISTEN_PID is not this process.
+// A LISTEN_FDNAMES without one name for each file descriptor is an
+// ErrListenFDNames, as with sd_listen_fds_with_names.
+// When `unset` is true, the activation variables are removed from the Env
+// afterwards, as sd_listen_fds does with the process environment. A nil Env
+// uses the env.Default
+func Files(e env.Env, unset bool) (files []*os.File, err error) {
+	if e == nil {
+		e = env.Default()
+	}
+	if unset {
+		defer func() {
+			e.Unset(ListenPID)
+			e.Unset(ListenFDs)
+			e.Unset(ListenFDNames)
+		}()
+	}
+
+	pid, pidPresent := e.Get(ListenPID)
+	count, countPresent := e.Get(ListenFDs)
+	if !pidPresent || !countPresent {
+		return
+	}
+	if v, ee := strconv.Atoi(strings.TrimSpace(pid)); ee != nil || v <= 0 {
+		err = fmt.Errorf("%w: %q", ErrListenPID, pid)
+		return
+	} else if v != os.Getpid() {
+		return
+	}
+	var n int
+	if n, err = strconv.Atoi(strings.TrimSpace(count)); err != nil || n < 0 {
+		err = fmt.Errorf("%w: %q", ErrListenFDs, count)
+		return
+	}
+
+	var names []string
+	if value, present := e.Get(ListenFDNames); present {
+		if names = strings.Split(value, ":"); len(names) != n {
+			err = fmt.Errorf("%w: %d names for %d file descriptors", ErrListenFDNames, len(names), n)
+			return
+		}
+	}
+	for idx := 0; idx < n; idx++ {
+		fd := ListenFDsStart + idx
+		closeOnExec(fd)
+		name := UnknownName
+		if names != nil && names[idx] != "" {
+			name = names[idx]
+		}
+		files = append(files, os.NewFile(uintptr(fd), name))
+	}
+	return
+}
+
+// Listeners returns a net.Listener for each of the Files, in order. Entries
+// for files which are not stream sockets are nil and, like all of the Files,
+// these are closed before returning
+func Listeners(e env.Env, unset bool) (listeners []net.Listener, err error) {
+	var files []*os.File
+	if files, err = Files(e, unset); err != nil {
+		return
+	}
+	listeners = make([]net.Listener, len(files))
+	for idx, file := range files {
+		if listener, ee := net.FileListener(file); ee == nil {
+			listeners[idx] = listener
+		}
+		_ = file.Close()
+	}
+	return
+}
+
+// ListenersByName is Listeners grouped by their LISTEN_FDNAMES names, in
+// order and without any nil entries. Files which are not stream sockets are
+// closed, use Files to keep them
+func ListenersByName(e env.Env, unset bool) (named map[string][]net.Listener, err error) {
+	var files []*os.File
+	if files, err = Files(e, unset); err != nil {
+		return
+	}
+	named = make(map[string][]net.Listener)
+	for _, file := range files {
+		if listener, ee := net.FileListener(file); ee == nil {
+			named[file.Name()] = append(named[file.Name()], listener)
+		}
+		_ = file.Close()
+	}
+	return
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package systemd
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/go-corelibs/env"
+)
+
+// activate duplicates the files onto high descriptors, starting from the
+// ListenFDsStart, as the service manager would
+func activate(files ...*os.File) {
+	for idx, file := range files {
+		So(syscall.Dup3(int(file.Fd()), ListenFDsStart+idx, syscall.O_CLOEXEC), ShouldBeNil)
+	}
+}
+
+func TestActivation(t *testing.T) {
+	Convey("socket activation", t, func() {
+		saved := ListenFDsStart
+		ListenFDsStart = 200
+		defer func() { ListenFDsStart = saved }()
+
+		tcp, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		So(err, ShouldBeNil)
+		defer tcp.Close()
+		tcpFile, err := tcp.File()
+		So(err, ShouldBeNil)
+		defer tcpFile.Close()
+		udp, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		So(err, ShouldBeNil)
+		defer udp.Close()
+		udpFile, err := udp.File()
+		So(err, ShouldBeNil)
+		defer udpFile.Close()
+
+		newEnv := func(pid int, names string) env.Env {
+			e := env.New()
+			e.Set(ListenPID, strconv.Itoa(pid))
+			e.Set(ListenFDs, "3")
+			if names != "" {
+				e.Set(ListenFDNames, names)
+			}
+			return e
+		}
+
+		Convey("Files", func() {
+			activate(tcpFile, udpFile, tcpFile)
+			e := newEnv(os.Getpid(), "http:dns:admin")
+			files, err := Files(e, false)
+			So(err, ShouldBeNil)
+			So(len(files), ShouldEqual, 3)
+			So(files[0].Fd(), ShouldEqual, uintptr(200))
+			So(files[0].Name(), ShouldEqual, "http")
+			So(files[1].Name(), ShouldEqual, "dns")
+			So(files[2].Name(), ShouldEqual, "admin")
+			_, present := e.Get(ListenFDs)
+			So(present, ShouldBeTrue)
+			for _, file := range files {
+				So(file.Close(), ShouldBeNil)
+			}
+
+			activate(tcpFile, udpFile, tcpFile)
+			e = newEnv(os.Getpid(), "")
+			files, err = Files(e, true)
+			So(err, ShouldBeNil)
+			So(files[0].Name(), ShouldEqual, UnknownName)
+			So(e.Len(), ShouldEqual, 0)
+			for _, file := range files {
+				So(file.Close(), ShouldBeNil)
+			}
+
+			e = newEnv(os.Getpid(), "too:few")
+			files, err = Files(e, true)
+			So(err, ShouldWrap, ErrListenFDNames)
+			So(files, ShouldBeNil)
+			So(e.Len(), ShouldEqual, 0)
+
+			e = newEnv(os.Getpid()+1, "")
+			files, err = Files(e, true)
+			So(err, ShouldBeNil)
+			So(files, ShouldBeNil)
+			So(e.Len(), ShouldEqual, 0)
+
+			files, err = Files(env.New(), false)
+			So(err, ShouldBeNil)
+			So(files, ShouldBeNil)
+
+			e = newEnv(0, "")
+			_, err = Files(e, false)
+			So(err, ShouldWrap, ErrListenPID)
+			e = newEnv(os.Getpid(), "")
+			e.Set(ListenFDs, "many")
+			_, err = Files(e, false)
+			So(err, ShouldWrap, ErrListenFDs)
+		})
+
+		Convey("Listeners", func() {
+			activate(tcpFile, udpFile)
+			e := newEnv(os.Getpid(), "http:dns")
+			e.Set(ListenFDs, "2")
+			listeners, err := Listeners(e, true)
+			So(err, ShouldBeNil)
+			So(len(listeners), ShouldEqual, 2)
+			So(listeners[0], ShouldNotBeNil)
+			So(listeners[0].Addr().String(), ShouldEqual, tcp.Addr().String())
+			So(listeners[1], ShouldBeNil)
+			So(e.Len(), ShouldEqual, 0)
+
+			conn, err := net.Dial("tcp", tcp.Addr().String())
+			So(err, ShouldBeNil)
+			defer conn.Close()
+			accepted, err := listeners[0].Accept()
+			So(err, ShouldBeNil)
+			So(accepted.Close(), ShouldBeNil)
+			So(listeners[0].Close(), ShouldBeNil)
+			var stat syscall.Stat_t
+			So(syscall.Fstat(201, &stat), ShouldEqual, syscall.EBADF)
+		})
+
+		Convey("ListenersByName", func() {
+			activate(tcpFile, udpFile, tcpFile)
+			named, err := ListenersByName(newEnv(os.Getpid(), "http:dns:http"), false)
+			So(err, ShouldBeNil)
+			So(len(named), ShouldEqual, 1)
+			So(len(named["http"]), ShouldEqual, 2)
+			for _, listener := range named["http"] {
+				So(listener.Addr().String(), ShouldEqual, tcp.Addr().String())
+				So(listener.Close(), ShouldBeNil)
+			}
+			var stat syscall.Stat_t
+			So(syscall.Fstat(201, &stat), ShouldEqual, syscall.EBADF)
+		})
+	})
+}
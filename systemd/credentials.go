@@ -0,0 +1,63 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package systemd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/go-corelibs/env"
+)
+
+// CredentialsDirectory is the variable naming the service credentials
+// directory
+const CredentialsDirectory = "CREDENTIALS_DIRECTORY"
+
+var ErrNoCredentials = errors.New("CREDENTIALS_DIRECTORY not set")
+
+// Credentials returns the service credentials as a new Env, one variable per
+// file in the CREDENTIALS_DIRECTORY, named with the file name and with the
+// exact file contents as the value. All the credentials are marked secret.
+// A nil Env uses the env.Default
+func Credentials(e env.Env) (credentials env.Env, err error) {
+	if e == nil {
+		e = env.Default()
+	}
+	dir, _ := e.Get(CredentialsDirectory)
+	if dir == "" {
+		err = ErrNoCredentials
+		return
+	}
+
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(dir); err != nil {
+		return
+	}
+	found := env.New()
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		var data []byte
+		if data, err = os.ReadFile(filepath.Join(dir, entry.Name())); err != nil {
+			return
+		}
+		found.Set(entry.Name(), string(data))
+		found.MarkSecret(entry.Name())
+	}
+	credentials = found
+	return
+}
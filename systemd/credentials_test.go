@@ -0,0 +1,48 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package systemd
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/go-corelibs/env"
+)
+
+func TestCredentials(t *testing.T) {
+	Convey("Credentials", t, func() {
+		tempDir, err := os.MkdirTemp("", "corelibs-env.*.d")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(tempDir)
+		So(os.WriteFile(tempDir+"/db.password", []byte("hunter2\n"), 0400), ShouldBeNil)
+		So(os.WriteFile(tempDir+"/api-token", []byte("token"), 0400), ShouldBeNil)
+		So(os.Mkdir(tempDir+"/subdir", 0700), ShouldBeNil)
+
+		e := env.New()
+		e.Set(CredentialsDirectory, tempDir)
+		credentials, err := Credentials(e)
+		So(err, ShouldBeNil)
+		So(credentials.Environ(), ShouldEqual, []string{"api-token=token", "db.password=hunter2\n"})
+		So(credentials.Redacted(), ShouldEqual, []string{"api-token=***", "db.password=***"})
+
+		_, err = Credentials(env.New())
+		So(err, ShouldEqual, ErrNoCredentials)
+		e.Set(CredentialsDirectory, tempDir+"/missing")
+		_, err = Credentials(e)
+		So(err, ShouldNotBeNil)
+	})
+}
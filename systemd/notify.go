@@ -0,0 +1,133 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-corelibs/env"
+)
+
+const (
+	// NotifySocket is the variable naming the service manager socket
+	NotifySocket = "NOTIFY_SOCKET"
+	// WatchdogUSec is the variable with the watchdog timeout in microseconds
+	WatchdogUSec = "WATCHDOG_USEC"
+	// WatchdogPID is the variable naming the process the watchdog is for
+	WatchdogPID = "WATCHDOG_PID"
+)
+
+var ErrWatchdogUSec = errors.New("invalid WATCHDOG_USEC")
+
+// Notify sends the `state`, newline separated "KEY=value" assignments, to
+// the datagram socket named by NOTIFY_SOCKET, as sd_notify does. A leading
+// "@" names a Linux abstract socket. Nothing is `sent` when NOTIFY_SOCKET
+// is not set. When `unset` is true, NOTIFY_SOCKET is removed from the Env
+// afterwards. A nil Env uses the env.Default
+func Notify(e env.Env, unset bool, state string) (sent bool, err error) {
+	if e == nil {
+		e = env.Default()
+	}
+	socket, _ := e.Get(NotifySocket)
+	if unset {
+		e.Unset(NotifySocket)
+	}
+	if socket == "" {
+		return
+	}
+	if socket[0] != '/' && socket[0] != '@' {
+		err = fmt.Errorf("unsupported %s address: %q", NotifySocket, socket)
+		return
+	}
+
+	var conn *net.UnixConn
+	if conn, err = net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"}); err != nil {
+		return
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte(state)); err == nil {
+		sent = true
+	}
+	return
+}
+
+// Ready tells the service manager that startup is complete
+func Ready(e env.Env) (sent bool, err error) {
+	sent, err = Notify(e, false, "READY=1")
+	return
+}
+
+// Stopping tells the service manager that shutdown has started
+func Stopping(e env.Env) (sent bool, err error) {
+	sent, err = Notify(e, false, "STOPPING=1")
+	return
+}
+
+// Status sends a free-form status message to the service manager
+func Status(e env.Env, message string) (sent bool, err error) {
+	sent, err = Notify(e, false, "STATUS="+message)
+	return
+}
+
+// Watchdog sends a keep-alive ping to the service manager, which should be
+// done at least every WatchdogInterval
+func Watchdog(e env.Env) (sent bool, err error) {
+	sent, err = Notify(e, false, "WATCHDOG=1")
+	return
+}
+
+// WatchdogInterval returns the watchdog timeout from WATCHDOG_USEC, like
+// sd_watchdog_enabled. The `interval` is zero when the watchdog is not
+// enabled or WATCHDOG_PID is set and is not this process. Services should
+// send Watchdog pings at half the interval. When `unset` is true, the
+// watchdog variables are removed from the Env afterwards. A nil Env uses the
+// env.Default
+func WatchdogInterval(e env.Env, unset bool) (interval time.Duration, err error) {
+	if e == nil {
+		e = env.Default()
+	}
+	if unset {
+		defer func() {
+			e.Unset(WatchdogUSec)
+			e.Unset(WatchdogPID)
+		}()
+	}
+
+	usec, present := e.Get(WatchdogUSec)
+	if !present {
+		return
+	}
+	var value uint64
+	if value, err = strconv.ParseUint(strings.TrimSpace(usec), 10, 64); err != nil || value == 0 {
+		err = fmt.Errorf("%w: %q", ErrWatchdogUSec, usec)
+		return
+	}
+	if pid, present := e.Get(WatchdogPID); present {
+		if v, ee := strconv.Atoi(strings.TrimSpace(pid)); ee != nil || v <= 0 {
+			err = fmt.Errorf("invalid %s: %q", WatchdogPID, pid)
+			return
+		} else if v != os.Getpid() {
+			return
+		}
+	}
+	interval = time.Duration(value) * time.Microsecond
+	return
+}
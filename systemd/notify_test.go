@@ -0,0 +1,120 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package systemd
+
+import (
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/go-corelibs/env"
+)
+
+func TestNotify(t *testing.T) {
+	Convey("Notify", t, func() {
+		tempDir, err := os.MkdirTemp("", "corelibs-env.*.d")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(tempDir)
+
+		receive := func(conn *net.UnixConn) string {
+			buf := make([]byte, 1024)
+			So(conn.SetReadDeadline(time.Now().Add(time.Second)), ShouldBeNil)
+			n, err := conn.Read(buf)
+			So(err, ShouldBeNil)
+			return string(buf[:n])
+		}
+
+		addresses := []string{tempDir + "/notify.sock"}
+		if runtime.GOOS == "linux" {
+			addresses = append(addresses, "@corelibs-env-notify-"+strconv.Itoa(os.Getpid()))
+		}
+		for _, address := range addresses {
+			conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: address, Net: "unixgram"})
+			So(err, ShouldBeNil)
+
+			e := env.New()
+			e.Set(NotifySocket, address)
+			sent, err := Ready(e)
+			So(err, ShouldBeNil)
+			So(sent, ShouldBeTrue)
+			So(receive(conn), ShouldEqual, "READY=1")
+			_, _ = Status(e, "serving")
+			So(receive(conn), ShouldEqual, "STATUS=serving")
+			_, _ = Watchdog(e)
+			So(receive(conn), ShouldEqual, "WATCHDOG=1")
+			_, _ = Stopping(e)
+			So(receive(conn), ShouldEqual, "STOPPING=1")
+			sent, err = Notify(e, true, "RELOADING=1\nMONOTONIC_USEC=1")
+			So(err, ShouldBeNil)
+			So(sent, ShouldBeTrue)
+			So(receive(conn), ShouldEqual, "RELOADING=1\nMONOTONIC_USEC=1")
+			So(e.Len(), ShouldEqual, 0)
+			So(conn.Close(), ShouldBeNil)
+
+			sent, err = Ready(e)
+			So(err, ShouldBeNil)
+			So(sent, ShouldBeFalse)
+		}
+
+		e := env.New()
+		e.Set(NotifySocket, tempDir+"/missing.sock")
+		sent, err := Ready(e)
+		So(err, ShouldNotBeNil)
+		So(sent, ShouldBeFalse)
+		e.Set(NotifySocket, "vsock:2:1")
+		_, err = Ready(e)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("WatchdogInterval", t, func() {
+		e := env.New()
+		interval, err := WatchdogInterval(e, false)
+		So(err, ShouldBeNil)
+		So(interval, ShouldEqual, 0)
+
+		e.Set(WatchdogUSec, "30000000")
+		interval, err = WatchdogInterval(e, false)
+		So(err, ShouldBeNil)
+		So(interval, ShouldEqual, 30*time.Second)
+
+		e.Set(WatchdogPID, strconv.Itoa(os.Getpid()))
+		interval, err = WatchdogInterval(e, false)
+		So(err, ShouldBeNil)
+		So(interval, ShouldEqual, 30*time.Second)
+
+		e.Set(WatchdogPID, strconv.Itoa(os.Getpid()+1))
+		interval, err = WatchdogInterval(e, true)
+		So(err, ShouldBeNil)
+		So(interval, ShouldEqual, 0)
+		So(e.Len(), ShouldEqual, 0)
+
+		for _, usec := range []string{"0", "-1", "soon"} {
+			e.Set(WatchdogUSec, usec)
+			_, err = WatchdogInterval(e, false)
+			So(err, ShouldWrap, ErrWatchdogUSec)
+		}
+		e.Set(WatchdogUSec, "1")
+		e.Set(WatchdogPID, "me")
+		_, err = WatchdogInterval(e, false)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSystemd(t *testing.T) {
+	Convey("ParseSystemdEnv", t, func() {
+		// the first cases are modelled after the env_file fixtures of
+		// systemd's src/test/test-env-file.c
+		for idx, test := range []struct {
+			input  string
+			output []string
+		}{
+			{
+				input: "a=a\n" +
+					"a=b\n" +
+					"a=b\n" +
+					"a=a\n" +
+					"b=b\\\n" +
+					"c\n" +
+					"d= d\\\n" +
+					"e  \\\n" +
+					"f  \n" +
+					"g=g\\ \n" +
+					"h= ąęół\\ śćńźżμ \n" +
+					"i=i\\",
+				output: []string{"a=a", "b=bc", "d=de  f", "g=g ", "h=ąęół śćńźżμ", "i=i"},
+			},
+			{
+				input:  "a=a\\\n",
+				output: []string{"a=a"},
+			},
+			{
+				input: "#SPAMD_ARGS=\"-d --socketpath=/var/lib/bulwark/spamd \\\n" +
+					"#--nouser-config                                     \\\n" +
+					"#--nouser-config                                     \\\n",
+				output: nil,
+			},
+			{
+				input: "# Generated\n" +
+					"\n" +
+					"HWMON_MODULES=\"coretemp f71882fg\"\n" +
+					"\n" +
+					"# For compatibility reasons\n" +
+					"\n" +
+					"MODULE_0=coretemp\n" +
+					"MODULE_1=f71882fg",
+				output: []string{"HWMON_MODULES=coretemp f71882fg", "MODULE_0=coretemp", "MODULE_1=f71882fg"},
+			},
+			{
+				input:  "a=\nb=",
+				output: []string{"a=", "b="},
+			},
+			{
+				input: "a=\\ \\n \\t \\x \\y \\' \n" +
+					"b= \\$'                  \n" +
+					"c= ' \\n\\t\\$\\`\\\\\n" +
+					"'   \n" +
+					"d= \" \\n\\t\\$\\`\\\\\n" +
+					"\"   \n",
+				output: []string{"a= n t x y '", "b=$'", "c= \\n\\t\\$\\`\\\\\n", "d= \\n\\t$`\\\n"},
+			},
+			{
+				// a comment ending in a backslash does not continue
+				input:  "# comment \\\nA=1\n; other \\\\\nB=2\n",
+				output: []string{"A=1", "B=2"},
+			},
+			{
+				input:  "  KEY  =  spaced value  \nNOEQUALS\nQUOTED='a'\"b\"c\nJOINED=\"x\" y\n",
+				output: []string{"KEY=spaced value", "QUOTED=abc", "JOINED=xy"},
+			},
+			{
+				// os-release style
+				input: "NAME=\"Fedora Linux\"\nVERSION_ID=40\nPRETTY_NAME='Fedora Linux 40'\n" +
+					"HOME_URL=\"https://fedoraproject.org/\"\n",
+				output: []string{
+					"NAME=Fedora Linux", "VERSION_ID=40", "PRETTY_NAME=Fedora Linux 40",
+					"HOME_URL=https://fedoraproject.org/",
+				},
+			},
+			{
+				input:  "1BAD=x\nBAD-NAME=x\nGOOD=x\nUNTERMINATED=\"open\nstill open",
+				output: []string{"GOOD=x", "UNTERMINATED=open\nstill open"},
+			},
+			{
+				input:  "A=1\x00B=2\n",
+				output: []string{"A=1"},
+			},
+		} {
+			Convey(fmt.Sprintf("case %d", idx), func() {
+				parsed, err := ParseSystemdEnv(strings.NewReader(test.input))
+				So(err, ShouldBeNil)
+				So(parsed.Environ(), ShouldEqual, test.output)
+			})
+		}
+
+		_, err := ParseSystemdEnv(strings.NewReader("A=1\nB=\xff\n"))
+		So(err, ShouldWrap, ErrInvalidUTF8)
+		So(err.Error(), ShouldEqual, "invalid utf-8: line 2")
+	})
+
+	Convey("Env.WriteSystemdEnv", t, func() {
+		env := New()
+		env.Set("PLAIN", "value")
+		env.Set("EMPTY", "")
+		env.Set("SPACED", "two words")
+		env.Set("SPECIAL", "a\"b\\c`d$e")
+		env.Set("GLOB", "*.go")
+		env.Set("MULTI", "line one\nline two")
+		env.Set("UNICODE", "ąęół")
+
+		var buf bytes.Buffer
+		So(env.WriteSystemdEnv(&buf), ShouldBeNil)
+		So(buf.String(), ShouldEqual, ""+
+			"PLAIN=value\n"+
+			"EMPTY=\n"+
+			"SPACED=\"two words\"\n"+
+			"SPECIAL=\"a\\\"b\\\\c\\`d\\$e\"\n"+
+			"GLOB=\"*.go\"\n"+
+			"MULTI=\"line one\nline two\"\n"+
+			"UNICODE=ąęół\n")
+
+		parsed, err := ParseSystemdEnv(&buf)
+		So(err, ShouldBeNil)
+		So(parsed.Environ(), ShouldEqual, env.Environ())
+
+		for _, variable := range []string{"BAD-NAME=x", "1BAD=x", "NUL=a\x00b"} {
+			invalid := New()
+			invalid.Set("VALID", "value")
+			key, value, _ := strings.Cut(variable, "=")
+			invalid.Set(key, value)
+			buf.Reset()
+			So(invalid.WriteSystemdEnv(&buf), ShouldWrap, ErrInvalidVariable)
+			So(buf.Len(), ShouldEqual, 0)
+		}
+	})
+}
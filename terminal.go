@@ -0,0 +1,148 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"strings"
+
+	"github.com/go-corelibs/slices"
+)
+
+// ColorDepth is the number of colours a terminal supports
+type ColorDepth uint8
+
+const (
+	ColorNone ColorDepth = iota
+	Color16
+	Color256
+	ColorTrue
+)
+
+func (d ColorDepth) String() (name string) {
+	switch d {
+	case ColorNone:
+		name = "none"
+	case Color16:
+		name = "16"
+	case Color256:
+		name = "256"
+	case ColorTrue:
+		name = "truecolor"
+	}
+	return
+}
+
+// Terminal describes the terminal capabilities found in an Env
+type Terminal struct {
+	// Term is the TERM value
+	Term string
+	// Program is the TERM_PROGRAM value
+	Program string
+	// Color is the colour depth to use
+	Color ColorDepth
+	// Forced is true when FORCE_COLOR or CLICOLOR_FORCE enable colour
+	Forced bool
+	// Disabled is true when colour is turned off by FORCE_COLOR=0,
+	// NO_COLOR, CLICOLOR=0 or TERM=dumb
+	Disabled bool
+	// Columns is the COLUMNS value, zero when not set or invalid
+	Columns int
+	// Lines is the LINES value, zero when not set or invalid
+	Lines int
+}
+
+// truecolorPrograms are TERM_PROGRAM values known to support 24-bit colour
+var truecolorPrograms = []string{"iTerm.app", "WezTerm", "vscode", "Hyper", "ghostty"}
+
+// Terminal applies the following precedence, the first rule matching
+// decides whether colour is forced or disabled:
+//
+//  1. FORCE_COLOR set to "0" or "false" disables colour, any other
+//     non-empty value forces it, with "2" and "3" requesting 256 colours
+//     and truecolor (https://force-color.org)
+//  2. CLICOLOR_FORCE set to anything other than "0" forces colour
+//     (https://bixense.com/clicolors)
+//  3. NO_COLOR set to any non-empty value disables colour
+//     (https://no-color.org)
+//  4. CLICOLOR set to "0" disables colour
+//  5. TERM set to "dumb" disables colour
+//
+// Otherwise the depth is detected from COLORTERM ("truecolor" or "24bit"),
+// TERM ("-direct", "truecolor" or "256color" suffixes) and TERM_PROGRAM,
+// with any other TERM value supporting 16 colours. Forced colour is never
+// less than 16 colours
+func (c *cEnv) Terminal() (terminal Terminal) {
+	terminal.Term = c.String("TERM", "")
+	terminal.Program = c.String("TERM_PROGRAM", "")
+	if terminal.Columns = c.Int("COLUMNS", 0); terminal.Columns < 0 {
+		terminal.Columns = 0
+	}
+	if terminal.Lines = c.Int("LINES", 0); terminal.Lines < 0 {
+		terminal.Lines = 0
+	}
+
+	detected := c.detectColor(terminal.Term, terminal.Program)
+	forced := ColorNone
+	force := c.String("FORCE_COLOR", "")
+	clicolorForce := c.String("CLICOLOR_FORCE", "")
+
+	switch {
+	case force == "0" || strings.EqualFold(force, "false"):
+		terminal.Disabled = true
+	case force != "":
+		terminal.Forced, forced = true, Color16
+		switch force {
+		case "2":
+			forced = Color256
+		case "3":
+			forced = ColorTrue
+		}
+	case clicolorForce != "" && clicolorForce != "0":
+		terminal.Forced, forced = true, Color16
+	case c.String("NO_COLOR", "") != "":
+		terminal.Disabled = true
+	case c.String("CLICOLOR", "") == "0":
+		terminal.Disabled = true
+	case terminal.Term == "dumb":
+		terminal.Disabled = true
+	}
+
+	switch {
+	case terminal.Disabled:
+		terminal.Color = ColorNone
+	case terminal.Forced:
+		terminal.Color = max(detected, forced)
+	default:
+		terminal.Color = detected
+	}
+	return
+}
+
+func (c *cEnv) detectColor(term, program string) (depth ColorDepth) {
+	colorterm := strings.ToLower(c.String("COLORTERM", ""))
+	switch {
+	case colorterm == "truecolor" || colorterm == "24bit":
+		depth = ColorTrue
+	case strings.HasSuffix(term, "-direct") || strings.Contains(term, "truecolor") || strings.Contains(term, "24bit"):
+		depth = ColorTrue
+	case slices.Within(program, truecolorPrograms):
+		depth = ColorTrue
+	case strings.Contains(term, "256color") || program == "Apple_Terminal":
+		depth = Color256
+	case term != "" && term != "dumb":
+		depth = Color16
+	}
+	return
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTerminal(t *testing.T) {
+	Convey("ColorDepth.String", t, func() {
+		So(ColorNone.String(), ShouldEqual, "none")
+		So(Color16.String(), ShouldEqual, "16")
+		So(Color256.String(), ShouldEqual, "256")
+		So(ColorTrue.String(), ShouldEqual, "truecolor")
+		So(ColorDepth(99).String(), ShouldEqual, "")
+	})
+
+	Convey("Env.Terminal", t, func() {
+		for idx, test := range []struct {
+			environ  string
+			color    ColorDepth
+			forced   bool
+			disabled bool
+		}{
+			{"", ColorNone, false, false},
+			{"TERM=dumb", ColorNone, false, true},
+			{"TERM=linux", Color16, false, false},
+			{"TERM=xterm-256color", Color256, false, false},
+			{"TERM=xterm-direct", ColorTrue, false, false},
+			{"TERM=xterm COLORTERM=truecolor", ColorTrue, false, false},
+			{"TERM=xterm COLORTERM=24bit", ColorTrue, false, false},
+			{"TERM=xterm-256color TERM_PROGRAM=Apple_Terminal", Color256, false, false},
+			{"TERM=xterm TERM_PROGRAM=Apple_Terminal", Color256, false, false},
+			{"TERM=xterm TERM_PROGRAM=iTerm.app", ColorTrue, false, false},
+			{"TERM=xterm-256color NO_COLOR=1", ColorNone, false, true},
+			{"TERM=xterm-256color NO_COLOR=", Color256, false, false},
+			{"TERM=xterm-256color CLICOLOR=0", ColorNone, false, true},
+			{"TERM=xterm-256color CLICOLOR=1", Color256, false, false},
+			{"NO_COLOR=1 CLICOLOR_FORCE=1", Color16, true, false},
+			{"TERM=xterm-256color NO_COLOR=1 CLICOLOR_FORCE=1", Color256, true, false},
+			{"TERM=dumb CLICOLOR_FORCE=1", Color16, true, false},
+			{"TERM=xterm CLICOLOR_FORCE=0", Color16, false, false},
+			{"FORCE_COLOR=1", Color16, true, false},
+			{"FORCE_COLOR=true", Color16, true, false},
+			{"FORCE_COLOR=2", Color256, true, false},
+			{"FORCE_COLOR=3", ColorTrue, true, false},
+			{"TERM=xterm-direct FORCE_COLOR=1", ColorTrue, true, false},
+			{"TERM=xterm-256color FORCE_COLOR=0", ColorNone, false, true},
+			{"TERM=xterm-256color FORCE_COLOR=false CLICOLOR_FORCE=1", ColorNone, false, true},
+			{"NO_COLOR=1 FORCE_COLOR=2", Color256, true, false},
+		} {
+			Convey(fmt.Sprintf("case %d: %q", idx, test.environ), func() {
+				terminal := NewImport(strings.Fields(test.environ)).Terminal()
+				So(terminal.Color, ShouldEqual, test.color)
+				So(terminal.Forced, ShouldEqual, test.forced)
+				So(terminal.Disabled, ShouldEqual, test.disabled)
+			})
+		}
+	})
+
+	Convey("Env.Terminal size hints", t, func() {
+		env := NewImport([]string{"TERM=screen", "TERM_PROGRAM=tmux", "COLUMNS=120", "LINES=40"})
+		So(env.Terminal(), ShouldEqual, Terminal{
+			Term:    "screen",
+			Program: "tmux",
+			Color:   Color16,
+			Columns: 120,
+			Lines:   40,
+		})
+		env = NewImport([]string{"COLUMNS=wide", "LINES=-1"})
+		terminal := env.Terminal()
+		So(terminal.Columns, ShouldEqual, 0)
+		So(terminal.Lines, ShouldEqual, 0)
+	})
+}
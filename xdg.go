@@ -0,0 +1,102 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// XDG holds the XDG Base Directory Specification locations. The home
+// directories are empty when neither the variable nor HOME are set and the
+// RuntimeDir has no default
+//
+// See: https://specifications.freedesktop.org/basedir-spec/latest/
+type XDG struct {
+	ConfigHome string
+	DataHome   string
+	StateHome  string
+	CacheHome  string
+	RuntimeDir string
+	ConfigDirs []string
+	DataDirs   []string
+}
+
+func (c *cEnv) XDG() (xdg XDG) {
+	home := c.String("HOME", "")
+	xdg.ConfigHome = c.xdgHome("XDG_CONFIG_HOME", home, ".config")
+	xdg.DataHome = c.xdgHome("XDG_DATA_HOME", home, ".local/share")
+	xdg.StateHome = c.xdgHome("XDG_STATE_HOME", home, ".local/state")
+	xdg.CacheHome = c.xdgHome("XDG_CACHE_HOME", home, ".cache")
+	xdg.RuntimeDir = c.xdgHome("XDG_RUNTIME_DIR", "", "")
+	xdg.ConfigDirs = c.xdgDirs("XDG_CONFIG_DIRS", "/etc/xdg")
+	xdg.DataDirs = c.xdgDirs("XDG_DATA_DIRS", "/usr/local/share", "/usr/share")
+	return
+}
+
+// SearchConfig looks for the `name` file or directory within the ConfigHome
+// followed by the ConfigDirs and returns the first one found
+func (x XDG) SearchConfig(name string) (path string, found bool) {
+	path, found = xdgSearch(name, x.ConfigHome, x.ConfigDirs)
+	return
+}
+
+// SearchData looks for the `name` file or directory within the DataHome
+// followed by the DataDirs and returns the first one found
+func (x XDG) SearchData(name string) (path string, found bool) {
+	path, found = xdgSearch(name, x.DataHome, x.DataDirs)
+	return
+}
+
+func xdgSearch(name, home string, dirs []string) (path string, found bool) {
+	for _, dir := range append([]string{home}, dirs...) {
+		if dir == "" {
+			continue
+		}
+		candidate := filepath.Join(dir, name)
+		if _, err := os.Stat(candidate); err == nil {
+			path, found = candidate, true
+			return
+		}
+	}
+	return
+}
+
+// xdgHome returns the absolute `key` value or the `fallback` within the
+// `home` directory
+func (c *cEnv) xdgHome(key, home, fallback string) (dir string) {
+	if dir = c.String(key, ""); filepath.IsAbs(dir) {
+		return
+	}
+	dir = ""
+	if home != "" && fallback != "" {
+		dir = filepath.Join(home, fallback)
+	}
+	return
+}
+
+// xdgDirs returns the absolute paths within the `key` list or the defaults
+// when there are none
+func (c *cEnv) xdgDirs(key string, defaults ...string) (dirs []string) {
+	for _, dir := range NewPathList(c, key, ":").Paths() {
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		dirs = defaults
+	}
+	return
+}
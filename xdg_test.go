@@ -0,0 +1,100 @@
+// Copyright (c) 2024  The Go-CoreLibs Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestXDG(t *testing.T) {
+	Convey("Env.XDG defaults", t, func() {
+		env := New()
+		env.Set("HOME", "/home/someone")
+		env.Set("XDG_CONFIG_HOME", "relative/is/ignored")
+		env.Set("XDG_DATA_DIRS", "relative:")
+		So(env.XDG(), ShouldEqual, XDG{
+			ConfigHome: "/home/someone/.config",
+			DataHome:   "/home/someone/.local/share",
+			StateHome:  "/home/someone/.local/state",
+			CacheHome:  "/home/someone/.cache",
+			ConfigDirs: []string{"/etc/xdg"},
+			DataDirs:   []string{"/usr/local/share", "/usr/share"},
+		})
+
+		So(New().XDG(), ShouldEqual, XDG{
+			ConfigDirs: []string{"/etc/xdg"},
+			DataDirs:   []string{"/usr/local/share", "/usr/share"},
+		})
+	})
+
+	Convey("Env.XDG variables", t, func() {
+		env := New()
+		env.Set("HOME", "/home/someone")
+		env.Set("XDG_CONFIG_HOME", "/cfg")
+		env.Set("XDG_DATA_HOME", "/data")
+		env.Set("XDG_STATE_HOME", "/state")
+		env.Set("XDG_CACHE_HOME", "/cache")
+		env.Set("XDG_RUNTIME_DIR", "/run/user/1000")
+		env.Set("XDG_CONFIG_DIRS", "/etc/one:relative:/etc/two")
+		env.Set("XDG_DATA_DIRS", "/usr/share")
+		So(env.XDG(), ShouldEqual, XDG{
+			ConfigHome: "/cfg",
+			DataHome:   "/data",
+			StateHome:  "/state",
+			CacheHome:  "/cache",
+			RuntimeDir: "/run/user/1000",
+			ConfigDirs: []string{"/etc/one", "/etc/two"},
+			DataDirs:   []string{"/usr/share"},
+		})
+	})
+
+	Convey("XDG.SearchConfig and XDG.SearchData", t, func() {
+		tempDir := t.TempDir()
+		for _, dir := range []string{"/home/.config/app", "/etc/one/app", "/etc/two/app", "/share/app"} {
+			So(os.MkdirAll(tempDir+dir, 0755), ShouldBeNil)
+		}
+		So(os.WriteFile(tempDir+"/etc/one/app/settings.toml", nil, 0644), ShouldBeNil)
+		So(os.WriteFile(tempDir+"/etc/two/app/settings.toml", nil, 0644), ShouldBeNil)
+		So(os.WriteFile(tempDir+"/home/.config/app/user.toml", nil, 0644), ShouldBeNil)
+		So(os.WriteFile(tempDir+"/share/app/icon.png", nil, 0644), ShouldBeNil)
+
+		env := New()
+		env.Set("HOME", tempDir+"/home")
+		env.Set("XDG_CONFIG_DIRS", tempDir+"/etc/one:"+tempDir+"/etc/two")
+		env.Set("XDG_DATA_DIRS", tempDir+"/share")
+		xdg := env.XDG()
+
+		path, found := xdg.SearchConfig("app/settings.toml")
+		So(found, ShouldBeTrue)
+		So(path, ShouldEqual, tempDir+"/etc/one/app/settings.toml")
+		path, found = xdg.SearchConfig("app/user.toml")
+		So(found, ShouldBeTrue)
+		So(path, ShouldEqual, tempDir+"/home/.config/app/user.toml")
+		path, found = xdg.SearchConfig("app")
+		So(found, ShouldBeTrue)
+		So(path, ShouldEqual, tempDir+"/home/.config/app")
+		_, found = xdg.SearchConfig("app/nope.toml")
+		So(found, ShouldBeFalse)
+
+		path, found = xdg.SearchData("app/icon.png")
+		So(found, ShouldBeTrue)
+		So(path, ShouldEqual, tempDir+"/share/app/icon.png")
+		_, found = XDG{}.SearchData("app/icon.png")
+		So(found, ShouldBeFalse)
+	})
+}